@@ -0,0 +1,224 @@
+package radit
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/oid-directory/go-radir"
+	"github.com/oid-directory/go-radit/internal/iso"
+)
+
+/*
+DefaultASN1Module contains the module reference written by [RADIT.WriteASN1]
+when none is specified.
+*/
+const DefaultASN1Module = `RADIT-Registrations`
+
+/*
+ASN1Options contains the settings which govern the content produced by the
+[RADIT.WriteASN1] method.
+*/
+type ASN1Options struct {
+	// WriteOptions governs the selection and order of registrations,
+	// as described for [RADIT.WriteLDIF]. Subentries and Registrants
+	// have no effect.
+	WriteOptions
+
+	// Module contains the module reference, which must begin with an
+	// uppercase letter. A zero value selects [DefaultASN1Module].
+	Module string
+}
+
+/*
+asn1Writer writes OBJECT IDENTIFIER value assignments, assigning a unique
+and legal value reference to each registration.
+*/
+type asn1Writer struct {
+	countWriter
+	err   error
+	opts  ASN1Options
+	names map[string]bool
+}
+
+func (r *asn1Writer) write(s string) {
+	if r.err == nil {
+		_, r.err = io.WriteString(r, s)
+	}
+}
+
+/*
+WriteASN1 writes an ASN.1 module containing one OBJECT IDENTIFIER value
+assignment per registration present within the receiver instance to the
+input [io.Writer] instance, returning the number of bytes written alongside
+an error, if any. For example:
+
+	RADIT-Registrations DEFINITIONS ::=
+	BEGIN
+
+	enterprise OBJECT IDENTIFIER ::= { iso(1) 3 6 1 4 1 } -- 1.3.6.1.4.1
+	enterprise-1 OBJECT IDENTIFIER ::= { enterprise 1 } -- 1.3.6.1.4.1.1
+
+	END
+
+Each value reference is derived from the X.680 identifier, or failing that
+the X.660 unicode value, of its registration, altered for legality as is done
+for SMI records during import. Registrations bearing no usable name are named
+after their superior and number form, as shown above. Should a name already
+have been assigned, the number form, and failing that the dotNotation, is
+appended to it.
+
+Roots are not assigned, as their names are defined by ITU-T Rec. X.680. The
+value of each base is therefore expressed relative to its root, while that of
+each subordinate is expressed relative to its nearest written superior.
+*/
+func (r *RADIT) WriteASN1(w io.Writer, opts ASN1Options) (n int64, err error) {
+	if r.IsZero() {
+		err = errors.New("RADIT instance is nil, aborting write")
+		return
+	} else if w == nil {
+		err = errors.New("io.Writer instance is nil, aborting write")
+		return
+	}
+
+	module := opts.Module
+	if module == "" {
+		module = DefaultASN1Module
+	} else if !isModuleReference(module) {
+		err = errors.New("Invalid ASN.1 module reference '" + module + "', aborting write")
+		return
+	}
+
+	bases, unlock, err := r.prepare(opts.WriteOptions)
+	defer unlock()
+	if err != nil {
+		return
+	}
+
+	aw := &asn1Writer{
+		countWriter: countWriter{w: w},
+		opts:        opts,
+		names:       make(map[string]bool),
+	}
+
+	aw.write(module + " DEFINITIONS ::=\nBEGIN\n\n")
+	for i := 0; i < len(bases) && aw.err == nil; i++ {
+		arcs := strings.Split(bases[i].X680().DotNotation(), `.`)
+		root := []string{r.dit.Resolve(arcs[0]).X680().NameAndNumberForm()}
+
+		if len(arcs) == 1 {
+			// Roots are never assigned.
+			aw.children(bases[i], root, ``, 0)
+		} else {
+			// Other bases are expressed relative to
+			// their root, e.g.: { iso(1) 3 6 1 4 1 }
+			aw.assign(bases[i], append(root, arcs[1:len(arcs)-1]...), ``, 0)
+		}
+	}
+	aw.write("\nEND\n")
+
+	n, err = aw.n, aw.err
+
+	return
+}
+
+/*
+assign writes the value assignment of the input registration, whose value
+is expressed relative to the input reference components, followed by those
+of its descendants. The name of the nearest written superior, if any, is
+used to name registrations which bear no usable name.
+*/
+func (r *asn1Writer) assign(reg *radir.Registration, ref []string, sup string, depth int) {
+	if reg.IsZero() || r.err != nil {
+		return
+	}
+
+	x680 := reg.X680()
+	comps := append(append([]string{}, ref...), x680.N())
+
+	omit, _ := skip(reg, depth, r.opts.WriteOptions)
+	if omit {
+		r.children(reg, comps, sup, depth)
+		return
+	}
+
+	name := r.name(reg, sup)
+	r.write(name + " OBJECT IDENTIFIER ::= { " + strings.Join(comps, ` `) +
+		" } -- " + x680.DotNotation() + "\n")
+	r.children(reg, []string{name}, name, depth)
+}
+
+func (r *asn1Writer) children(reg *radir.Registration, ref []string, sup string, depth int) {
+	if _, deep := skip(reg, depth, r.opts.WriteOptions); deep {
+		return
+	}
+
+	kids := reg.Children()
+	for i := 0; i < kids.Len() && r.err == nil; i++ {
+		r.assign(kids.Index(i), ref, sup, depth+1)
+	}
+}
+
+/*
+name returns a unique, legal value reference for the input registration,
+which is recorded as having been assigned.
+*/
+func (r *asn1Writer) name(reg *radir.Registration, sup string) (name string) {
+	x680 := reg.X680()
+	dot, n := x680.DotNotation(), x680.N()
+
+	if name = iso.LegalizeIdentifier(x680.Identifier()); name == "" {
+		name = iso.LegalizeIdentifier(reg.X660().UnicodeValue())
+	}
+
+	if name == "" {
+		if sup != "" {
+			name = sup + `-` + n
+		} else {
+			name = `oid-` + strings.ReplaceAll(dot, `.`, `-`)
+		}
+	}
+
+	// Should every candidate be taken, the last is suffixed
+	// with an ever-increasing number until unique.
+	cands := []string{
+		name,
+		name + `-` + n,
+		name + `-` + strings.ReplaceAll(dot, `.`, `-`),
+	}
+	try := cands[0]
+	for i := 1; r.names[try]; i++ {
+		if i < len(cands) {
+			try = cands[i]
+		} else {
+			try = cands[len(cands)-1] + `-` + strconv.Itoa(i-len(cands)+2)
+		}
+	}
+	name = try
+
+	r.names[name] = true
+
+	return
+}
+
+/*
+isModuleReference returns a Boolean value indicative of the input value being
+a legal ASN.1 module reference: an uppercase letter followed by any number of
+letters, digits and single hyphens, not ending with a hyphen.
+*/
+func isModuleReference(ref string) bool {
+	if ref == "" || !('A' <= ref[0] && ref[0] <= 'Z') ||
+		strings.HasSuffix(ref, `-`) || strings.Contains(ref, `--`) {
+		return false
+	}
+
+	for _, c := range ref {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' ||
+			'0' <= c && c <= '9' || c == '-') {
+			return false
+		}
+	}
+
+	return true
+}
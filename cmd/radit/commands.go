@@ -0,0 +1,402 @@
+package main
+
+/*
+commands.go implements each of the subcommands listed within main.go.
+*/
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/oid-directory/go-radir"
+	"github.com/oid-directory/go-radit"
+)
+
+/*
+build imports the specified sources and writes an LDIF dump which, by default,
+is sorted, spatially ordered and includes subentries and registrants.
+*/
+func build(fs *flag.FlagSet, args []string, stdout io.Writer) (err error) {
+	var src sourceFlags
+	out := writeFlags{stdout: stdout}
+	src.register(fs)
+	out.register(fs, true)
+	if err = parse(fs, args, 0); err != nil {
+		return
+	}
+
+	var dit *radit.RADIT
+	if dit, err = src.load(); err != nil {
+		return
+	}
+
+	return out.create(func(w io.Writer) (err error) {
+		_, err = dit.WriteLDIF(w, out.options())
+		return
+	})
+}
+
+/*
+export imports the specified sources and writes a dump in the requested
+format.
+*/
+func export(fs *flag.FlagSet, args []string, stdout io.Writer) (err error) {
+	out := writeFlags{stdout: stdout}
+	var (
+		src     sourceFlags
+		format  string
+		columns listFlag
+		comma   string
+		module  string
+		schema  string
+		name    string
+		stubs   bool
+		pkg     string
+		typ     string
+		prefix  string
+		asn1    bool
+		color   string
+		fanout  int
+	)
+
+	src.register(fs)
+	out.register(fs, false)
+	fs.StringVar(&format, `format`, `ldif`, "output `format`: ldif, json, jsonl, csv, asn1, schema, go, dot or mermaid")
+	fs.Var(&columns, `columns`, "comma-delimited CSV `columns` (default: all)")
+	fs.StringVar(&comma, `comma`, `,`, "CSV field `delimiter`")
+	fs.StringVar(&module, `module`, radit.DefaultASN1Module, "ASN.1 module `reference`")
+	fs.StringVar(&schema, `schema-format`, `slapd`, "schema `dialect`: slapd, olc or 389")
+	fs.StringVar(&name, `schema-name`, ``, "cn=config schema entry `name` (default: radit)")
+	fs.BoolVar(&stubs, `stubs`, false, `write schema definition stubs`)
+	fs.StringVar(&pkg, `package`, `oids`, "Go package `name`")
+	fs.StringVar(&typ, `type`, `OID`, "Go constant `type` name")
+	fs.StringVar(&prefix, `prefix`, ``, "Go constant name `prefix`")
+	fs.BoolVar(&asn1, `asn1`, false, `also write encoding/asn1.ObjectIdentifier variables`)
+	fs.StringVar(&color, `color`, `none`, "diagram node `coloring`: none, status or source")
+	fs.IntVar(&fanout, `max-children`, 0, "maximum `children` drawn beneath any one registration (0: no limit)")
+	if err = parse(fs, args, 0); err != nil {
+		return
+	}
+
+	var write func(*radit.RADIT, io.Writer) (int64, error)
+	opts := out.options()
+
+	switch strings.ToLower(format) {
+	case `ldif`:
+		write = func(dit *radit.RADIT, w io.Writer) (int64, error) {
+			return dit.WriteLDIF(w, opts)
+		}
+	case `json`:
+		write = func(dit *radit.RADIT, w io.Writer) (int64, error) {
+			return dit.WriteJSON(w, opts)
+		}
+	case `jsonl`:
+		write = func(dit *radit.RADIT, w io.Writer) (int64, error) {
+			return dit.WriteJSONLines(w, opts)
+		}
+	case `csv`:
+		r, size := utf8.DecodeRuneInString(comma)
+		if size == 0 || size != len(comma) {
+			return errors.New("CSV delimiter must be a single character")
+		}
+		write = func(dit *radit.RADIT, w io.Writer) (int64, error) {
+			return dit.WriteCSV(w, radit.CSVOptions{WriteOptions: opts, Columns: columns, Comma: r})
+		}
+	case `asn1`:
+		write = func(dit *radit.RADIT, w io.Writer) (int64, error) {
+			return dit.WriteASN1(w, radit.ASN1Options{WriteOptions: opts, Module: module})
+		}
+	case `schema`:
+		formats := map[string]radit.SchemaFormat{
+			`slapd`: radit.SchemaSlapd,
+			`olc`:   radit.SchemaOLC,
+			`389`:   radit.Schema389,
+		}
+		sf, found := formats[strings.ToLower(schema)]
+		if !found {
+			return errors.New("unknown schema format '" + schema + "'")
+		}
+		write = func(dit *radit.RADIT, w io.Writer) (int64, error) {
+			return dit.WriteSchema(w, radit.SchemaOptions{
+				WriteOptions: opts,
+				Format:       sf,
+				Name:         name,
+				Stubs:        stubs,
+			})
+		}
+	case `go`:
+		write = func(dit *radit.RADIT, w io.Writer) (int64, error) {
+			return dit.WriteGo(w, radit.GoOptions{
+				WriteOptions: opts,
+				Package:      pkg,
+				Type:         typ,
+				Prefix:       prefix,
+				ASN1:         asn1,
+			})
+		}
+	case `dot`, `mermaid`:
+		colors := map[string]radit.DiagramColor{
+			`none`:   radit.ColorNone,
+			`status`: radit.ColorByStatus,
+			`source`: radit.ColorBySource,
+		}
+		dc, found := colors[strings.ToLower(color)]
+		if !found {
+			return errors.New("unknown diagram coloring '" + color + "'")
+		}
+		dopts := radit.DiagramOptions{WriteOptions: opts, MaxChildren: fanout, Color: dc}
+		write = func(dit *radit.RADIT, w io.Writer) (int64, error) {
+			if strings.EqualFold(format, `dot`) {
+				return dit.WriteDOT(w, dopts)
+			}
+			return dit.WriteMermaid(w, dopts)
+		}
+	default:
+		return errors.New("unknown export format '" + format + "'")
+	}
+
+	var dit *radit.RADIT
+	if dit, err = src.load(); err != nil {
+		return
+	}
+
+	return out.create(func(w io.Writer) (err error) {
+		_, err = write(dit, w)
+		return
+	})
+}
+
+/*
+lookup imports the specified sources and describes the registration named by
+the positional argument(s), which may be a dotNotation, an ASN.1 notation, an
+OID-IRI or a path of identifiers, e.g.:
+
+	radit lookup 1.3.6.1.4.1
+	radit lookup '{iso identified-organization dod internet private}'
+	radit lookup /ISO/Identified-Organization/6/1/4/1
+	radit lookup iso identified-organization dod internet private
+*/
+func lookup(fs *flag.FlagSet, args []string, stdout io.Writer) (err error) {
+	var src sourceFlags
+	src.register(fs)
+	if err = parse(fs, args, -1); err != nil {
+		return
+	} else if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	var dit *radit.RADIT
+	if dit, err = src.load(); err != nil {
+		return
+	}
+
+	var reg *radir.Registration
+	if reg, err = find(dit, fs.Args()...); err != nil {
+		return
+	}
+
+	x680, x660, sup := reg.X680(), reg.X660(), reg.Supplement()
+	for _, field := range []struct {
+		name string
+		vals []string
+	}{
+		{`dotNotation`, []string{x680.DotNotation()}},
+		{`identifier`, []string{x680.Identifier()}},
+		{`nameAndNumberForm`, []string{x680.NameAndNumberForm()}},
+		{`asn1Notation`, []string{x680.ASN1Notation()}},
+		{`iri`, []string{x680.IRI()}},
+		{`unicodeValue`, []string{x660.UnicodeValue()}},
+		{`description`, []string{reg.Description()}},
+		{`status`, []string{sup.Status()}},
+		{`range`, []string{sup.Range()}},
+		{`uri`, sup.URI()},
+		{`info`, sup.Info()},
+		{`children`, []string{strconv.Itoa(reg.Children().Len())}},
+	} {
+		for _, val := range field.vals {
+			if val != "" {
+				fmt.Fprintf(stdout, "%-18s %s\n", field.name+`:`, val)
+			}
+		}
+	}
+
+	return
+}
+
+/*
+find returns the registration identified by the input arguments, as described
+for lookup.
+*/
+func find(dit *radit.RADIT, args ...string) (*radir.Registration, error) {
+	arg := strings.TrimSpace(args[0])
+	switch {
+	case len(args) > 1:
+		return dit.LookupIdentifiers(args...)
+	case strings.HasPrefix(arg, `{`):
+		return dit.LookupASN1(arg)
+	case strings.HasPrefix(arg, `/`):
+		return dit.LookupIRI(arg)
+	case strings.Trim(arg, `0123456789.`) == "":
+		return dit.Lookup(arg)
+	}
+
+	return dit.LookupIdentifiers(arg)
+}
+
+/*
+tree imports the specified sources and prints the subtree beneath the
+registration named by the positional argument. See [radit.RADIT.WriteTree].
+*/
+func tree(fs *flag.FlagSet, args []string, stdout io.Writer) (err error) {
+	var (
+		src    sourceFlags
+		opts   radit.TreeOptions
+		output string
+	)
+
+	src.register(fs)
+	fs.IntVar(&opts.MaxDepth, `depth`, 1, "maximum `levels` printed beneath the OID (0: no limit)")
+	fs.IntVar(&opts.MaxChildren, `max-children`, 20, "maximum `children` printed beneath any one registration (0: no limit)")
+	fs.BoolVar(&opts.SortByNumberForm, `sort`, false, `sort registrations by number form`)
+	fs.BoolVar(&opts.Indent, `indent`, false, `use indentation rather than box-drawing characters`)
+	fs.BoolVar(&opts.Description, `desc`, false, `print the description of each registration`)
+	fs.BoolVar(&opts.Status, `status`, false, `print the status of each registration`)
+	fs.BoolVar(&opts.Source, `source`, false, `print the source of each registration`)
+	fs.StringVar(&output, `o`, `-`, "output `path` (-: standard output)")
+	if err = parse(fs, args, 1); err != nil {
+		return
+	}
+
+	var dit *radit.RADIT
+	if dit, err = src.load(); err != nil {
+		return
+	}
+
+	var reg *radir.Registration
+	if reg, err = find(dit, fs.Arg(0)); err != nil {
+		return
+	}
+	opts.Bases = []string{reg.X680().DotNotation()}
+
+	out := writeFlags{output: output, stdout: stdout}
+	return out.create(func(w io.Writer) (err error) {
+		_, err = dit.WriteTree(w, opts)
+		return
+	})
+}
+
+/*
+diff loads two dumps, each of which may be LDIF, JSON or CSV, and writes the
+LDIF change records which transform the first into the second.
+*/
+func diff(fs *flag.FlagSet, args []string, stdout io.Writer) (err error) {
+	var prof profileFlags
+	var output string
+	prof.register(fs)
+	fs.StringVar(&output, `o`, `-`, "output `path` (-: standard output)")
+	if err = parse(fs, args, 2); err != nil {
+		return
+	}
+
+	dits := make([]*radit.RADIT, 2)
+	for i, path := range fs.Args() {
+		if dits[i], err = prof.new(); err != nil {
+			return
+		}
+
+		if err = dits[i].Import(radit.ImportList{dumpKey(path): path}); err != nil {
+			return errors.New(path + ": " + err.Error())
+		}
+	}
+
+	out := writeFlags{output: output, stdout: stdout}
+	return out.create(func(w io.Writer) (err error) {
+		_, err = radit.Diff(w, dits[0], dits[1])
+		return
+	})
+}
+
+/*
+dumpKey returns the [radit.ImportList] key appropriate for the input path,
+based upon its extension.
+*/
+func dumpKey(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case `.json`, `.jsonl`:
+		return `jsonfile`
+	case `.csv`:
+		return `csvfile`
+	}
+
+	return `ldiffile`
+}
+
+/*
+validate imports the specified sources and reports the number of records
+skipped or altered, and the irregularities tolerated, for each source. With
+-strict, any such record results in a non-zero exit status.
+*/
+func validate(fs *flag.FlagSet, args []string, stdout io.Writer) (err error) {
+	var src sourceFlags
+	var strict, verbose bool
+	src.register(fs)
+	fs.BoolVar(&strict, `strict`, false, `fail if any record was skipped, rewritten or tolerated`)
+	fs.BoolVar(&verbose, `v`, false, `list each skipped record, rewrite and warning`)
+	if err = parse(fs, args, 0); err != nil {
+		return
+	} else if len(src.list()) == 0 {
+		fmt.Fprintln(fs.Output(), "no sources specified")
+		fs.Usage()
+		return errUsage
+	}
+
+	var dit *radit.RADIT
+	if dit, err = src.load(); err != nil {
+		return
+	}
+
+	report := dit.Report()
+	counts := report.Counts()
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var total int
+	for _, key := range keys {
+		c := counts[key]
+		total += c.Skipped + c.Rewrites + c.Warnings
+		fmt.Fprintf(stdout, "%s: %d skipped, %d rewritten, %d warnings\n",
+			key, c.Skipped, c.Rewrites, c.Warnings)
+	}
+
+	if verbose {
+		for _, skip := range report.Skipped {
+			fmt.Fprintf(stdout, "%s: skipped %s %s: %s\n", skip.Source, skip.Registry, skip.Value, skip.Reason)
+		}
+		for _, rw := range report.Rewrites {
+			fmt.Fprintf(stdout, "%s: rewrote %s %s %q as %q (%s)\n",
+				rw.Source, rw.Registry, rw.Value, rw.Original, rw.Identifier, strings.Join(rw.Methods, ` > `))
+		}
+		for _, warn := range report.Warnings {
+			fmt.Fprintf(stdout, "%s: line %d: %s\n", warn.Source, warn.Line, warn.Message)
+		}
+	}
+
+	if total == 0 {
+		fmt.Fprintln(stdout, "ok")
+	} else if strict {
+		err = errors.New(strconv.Itoa(total) + " record(s) skipped, rewritten or tolerated")
+	}
+
+	return
+}
@@ -0,0 +1,281 @@
+package main
+
+/*
+flags.go defines the groups of flags shared by several commands.
+*/
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/oid-directory/go-radit"
+)
+
+/*
+parse parses the input arguments using the input [flag.FlagSet], requiring
+exactly the input number of positional arguments. A negative number permits
+any number of positional arguments.
+*/
+func parse(fs *flag.FlagSet, args []string, nargs int) (err error) {
+	if err = fs.Parse(args); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			err = errUsage
+		}
+	} else if nargs >= 0 && fs.NArg() != nargs {
+		fmt.Fprintf(fs.Output(), "expected %d argument(s), got %d\n", nargs, fs.NArg())
+		fs.Usage()
+		err = errUsage
+	}
+
+	return
+}
+
+/*
+listFlag is a [flag.Value] which accumulates comma-delimited values across
+any number of occurrences of a flag.
+*/
+type listFlag []string
+
+func (r *listFlag) String() string {
+	return strings.Join(*r, `,`)
+}
+
+func (r *listFlag) Set(val string) error {
+	for _, v := range strings.Split(val, `,`) {
+		if v = strings.TrimSpace(v); v != "" {
+			*r = append(*r, v)
+		}
+	}
+
+	return nil
+}
+
+/*
+profileFlags contains the settings of the [radir.DITProfile] used by each
+command. Settings read from the profile configuration file, if any, are
+overridden by those specified by flag. Unset settings retain the factory
+default values.
+*/
+type profileFlags struct {
+	config string
+	radit.ProfileConfig
+}
+
+func (r *profileFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&r.config, `profile`, ``, "`path` of a JSON or YAML profile configuration")
+	fs.StringVar(&r.RegistrationBase, `registration-base`, ``, "registration base `DN`")
+	fs.StringVar(&r.RegistrantBase, `registrant-base`, ``, "registrant base `DN`")
+	fs.StringVar(&r.Model, `model`, ``, "DIT `model`: 2D or 3D")
+	fs.StringVar(&r.RegistrantsPolicy, `policy`, ``, "registrants `policy`: dedicated or combined")
+}
+
+/*
+new returns a new *[radit.RADIT] instance bearing the profile described by
+the receiver.
+*/
+func (r *profileFlags) new() (dit *radit.RADIT, err error) {
+	var cfg radit.ProfileConfig
+	if r.config != "" {
+		if cfg, err = radit.LoadProfileConfig(r.config); err != nil {
+			return
+		}
+	}
+
+	for _, s := range []struct {
+		dst *string
+		src string
+	}{
+		{&cfg.RegistrationBase, r.RegistrationBase},
+		{&cfg.RegistrantBase, r.RegistrantBase},
+		{&cfg.Model, r.Model},
+		{&cfg.RegistrantsPolicy, r.RegistrantsPolicy},
+	} {
+		if s.src != "" {
+			*s.dst = s.src
+		}
+	}
+
+	return radit.NewFromConfig(cfg)
+}
+
+/*
+sourceFlags contains the sources imported by each command, alongside the
+roots to prime beforehand and the import options.
+*/
+type sourceFlags struct {
+	profileFlags
+	files        map[string]*string
+	prime        listFlag
+	noPrime      bool
+	concurrent   bool
+	keepObsolete bool
+	strictPEN    bool
+}
+
+/*
+sourceKeys maps the name of each source flag to its [radit.ImportList] key.
+*/
+var sourceKeys = []struct{ flag, key, desc string }{
+	{`ldif`, `ldiffile`, `LDIF dump previously written by radit`},
+	{`json`, `jsonfile`, `JSON or JSON Lines dump previously written by radit`},
+	{`smi`, `smifile`, `IANA SMI registry XML`},
+	{`ldap`, `ldapfile`, `IANA LDAP registry XML`},
+	{`pen`, `penfile`, `IANA PEN registry TXT`},
+	{`csv`, `csvfile`, `CSV of custom registrations`},
+}
+
+func (r *sourceFlags) register(fs *flag.FlagSet) {
+	r.profileFlags.register(fs)
+
+	r.files = make(map[string]*string, len(sourceKeys))
+	for _, src := range sourceKeys {
+		r.files[src.key] = fs.String(src.flag, ``, "`path` of the "+src.desc)
+	}
+
+	fs.Var(&r.prime, `prime`, "comma-delimited `roots` to prime: itu-t, iso, joint-iso-itu-t (default: all)")
+	fs.BoolVar(&r.noPrime, `no-prime`, false, `do not prime any root`)
+	fs.BoolVar(&r.concurrent, `concurrent`, false, `parse sources concurrently`)
+	fs.BoolVar(&r.keepObsolete, `keep-obsolete`, false, `retain obsolete SMI records`)
+	fs.BoolVar(&r.strictPEN, `strict-pen`, false, `reject irregular PEN registry entries`)
+}
+
+/*
+list returns the [radit.ImportList] described by the receiver.
+*/
+func (r *sourceFlags) list() (imp radit.ImportList) {
+	imp = make(radit.ImportList)
+	for key, path := range r.files {
+		if *path != "" {
+			imp[key] = *path
+		}
+	}
+
+	return
+}
+
+/*
+load returns a new *[radit.RADIT] instance, primed and populated as described
+by the receiver.
+*/
+func (r *sourceFlags) load() (dit *radit.RADIT, err error) {
+	if dit, err = r.new(); err != nil {
+		return
+	}
+
+	dit.SetImportOptions(radit.ImportOptions{
+		KeepObsolete: r.keepObsolete,
+		StrictPEN:    r.strictPEN,
+		Concurrent:   r.concurrent,
+	})
+
+	if err = r.primeRoots(dit); err != nil {
+		return
+	}
+
+	if imp := r.list(); len(imp) > 0 {
+		err = dit.Import(imp)
+	}
+
+	return
+}
+
+func (r *sourceFlags) primeRoots(dit *radit.RADIT) error {
+	if r.noPrime {
+		return nil
+	}
+
+	roots := r.prime
+	if len(roots) == 0 {
+		roots = listFlag{`itu-t`, `iso`, `joint-iso-itu-t`}
+	}
+
+	for _, root := range roots {
+		switch strings.ToLower(root) {
+		case `0`, `itu-t`:
+			dit.PrimeITUT()
+		case `1`, `iso`:
+			dit.PrimeISO()
+		case `2`, `joint-iso-itu-t`:
+			dit.PrimeJointISOITUT()
+		default:
+			return errors.New("unknown root '" + root + "'")
+		}
+	}
+
+	return nil
+}
+
+/*
+writeFlags contains the [radit.WriteOptions] used by each exporting command,
+alongside the output path and the standard output of the command.
+*/
+type writeFlags struct {
+	stdout      io.Writer
+	sort        bool
+	spatial     bool
+	subentries  bool
+	registrants bool
+	bases       listFlag
+	maxDepth    int
+	output      string
+}
+
+/*
+register registers the receiver's flags. The input Boolean value is used as
+the default of the sort, spatial, subentries and registrants flags.
+*/
+func (r *writeFlags) register(fs *flag.FlagSet, all bool) {
+	fs.BoolVar(&r.sort, `sort`, all, `sort registrations by number form`)
+	fs.BoolVar(&r.spatial, `spatial`, all, `order registrations along the X and Y axes`)
+	fs.BoolVar(&r.subentries, `subentries`, all, `include subentries (LDIF only)`)
+	fs.BoolVar(&r.registrants, `registrants`, all, `include registrants`)
+	fs.Var(&r.bases, `base`, "comma-delimited `OIDs` or root names to write (default: all roots)")
+	fs.IntVar(&r.maxDepth, `max-depth`, 0, "maximum `levels` written beneath each base (0: no limit)")
+	fs.StringVar(&r.output, `o`, `-`, "output `path` (-: standard output)")
+}
+
+func (r *writeFlags) options() radit.WriteOptions {
+	return radit.WriteOptions{
+		SortByNumberForm: r.sort,
+		SpatialXY:        r.spatial,
+		Subentries:       r.subentries,
+		Registrants:      r.registrants,
+		Bases:            r.bases,
+		MaxDepth:         r.maxDepth,
+	}
+}
+
+/*
+create calls the input function with a buffered writer to the output path,
+or to the standard output of the command if the path is "-", which is flushed
+and, if a file, closed once the function returns.
+*/
+func (r *writeFlags) create(funk func(io.Writer) error) (err error) {
+	w := r.stdout
+	var f *os.File
+	if r.output != `-` && r.output != "" {
+		if f, err = os.Create(r.output); err != nil {
+			return
+		}
+		w = f
+	}
+
+	bw := bufio.NewWriter(w)
+	err = funk(bw)
+	if ferr := bw.Flush(); err == nil {
+		err = ferr
+	}
+
+	if f != nil {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	return
+}
@@ -0,0 +1,125 @@
+/*
+Command radit builds, exports and inspects OID directory information trees
+by way of the radit package, without the need to write any Go.
+
+Usage:
+
+	radit <command> [flags] [arguments]
+
+The commands are:
+
+	build     import sources and write a complete LDIF dump
+	export    import sources and write a dump in the requested format
+	lookup    import sources and describe a single registration
+	tree      import sources and print a subtree
+	diff      compare two dumps and write LDIF change records
+	validate  import sources and report skipped or altered records
+
+Run "radit <command> -h" for the flags understood by each command.
+
+For example, the following produces the same dump as _examples/main.go:
+
+	$ radit build -smi smi.xml -ldap ldap.xml -pen pen.txt -o ra.ldif
+
+The DIT profile may be read from a JSON or YAML file by way of the -profile
+flag, and any of its settings overridden by way of the -registration-base,
+-registrant-base, -model and -policy flags. See radit.ReadProfileConfig.
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+/*
+command describes a single subcommand.
+*/
+type command struct {
+	name    string
+	args    string // synopsis of positional arguments, if any
+	summary string
+	run     func(*flag.FlagSet, []string, io.Writer) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{`build`, ``, `import sources and write a complete LDIF dump`, build},
+		{`export`, ``, `import sources and write a dump in the requested format`, export},
+		{`lookup`, `<oid>`, `import sources and describe a single registration`, lookup},
+		{`tree`, `<oid>`, `import sources and print a subtree`, tree},
+		{`diff`, `<old> <new>`, `compare two dumps and write LDIF change records`, diff},
+		{`validate`, ``, `import sources and report skipped or altered records`, validate},
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+/*
+run executes the command named by the first of the input arguments, writing
+its output to stdout and any usage or error messages to stderr, and returns
+the exit status: zero upon success, one upon failure and two upon invalid
+usage.
+*/
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) < 1 {
+		usage(stderr)
+		return 2
+	}
+
+	name := args[0]
+	if name == `help` || name == `-h` || name == `-help` || name == `--help` {
+		usage(stdout)
+		return 0
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+
+		fs := flag.NewFlagSet(`radit `+cmd.name, flag.ContinueOnError)
+		fs.SetOutput(stderr)
+		fs.Usage = func() {
+			fmt.Fprintf(fs.Output(), "Usage: radit %s [flags] %s\n\n%s.\n\nFlags:\n",
+				cmd.name, cmd.args, cmd.summary)
+			fs.PrintDefaults()
+		}
+
+		err := cmd.run(fs, args[1:], stdout)
+		switch {
+		case err == nil, errors.Is(err, flag.ErrHelp):
+			return 0
+		case errors.Is(err, errUsage):
+			return 2
+		}
+
+		fmt.Fprintln(stderr, `radit: `+err.Error())
+		return 1
+	}
+
+	fmt.Fprintf(stderr, "radit: unknown command %q\n", name)
+	usage(stderr)
+	return 2
+}
+
+/*
+errUsage is returned by a command whose flags or arguments were invalid, and
+whose usage has therefore already been printed.
+*/
+var errUsage = errors.New(`invalid usage`)
+
+func usage(w io.Writer) {
+	fmt.Fprint(w, "Usage: radit <command> [flags] [arguments]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-9s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprint(w, "\nRun \"radit <command> -h\" for the flags understood by each command.\n")
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/oid-directory/go-radit"
+)
+
+/*
+testdata returns the path of the named file within the testdata folder of
+the radit package.
+*/
+func testdata(name string) string {
+	return filepath.Join(`..`, `..`, `testdata`, name)
+}
+
+func TestParse(t *testing.T) {
+	for idx, strukt := range []struct {
+		Args  []string
+		NArgs int
+		Err   error
+	}{
+		{[]string{`-o`, `out.ldif`}, 0, nil},
+		{[]string{`1.3.6.1`}, 1, nil},
+		{[]string{`a`, `b`, `c`}, -1, nil},
+		{[]string{`1.3.6.1`}, 0, errUsage},
+		{[]string{}, 2, errUsage},
+		{[]string{`-bogus`}, 0, errUsage},
+		{[]string{`-h`}, 0, flag.ErrHelp},
+	} {
+		fs := flag.NewFlagSet(`test`, flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		fs.String(`o`, `-`, ``)
+
+		if err := parse(fs, strukt.Args, strukt.NArgs); !errors.Is(err, strukt.Err) {
+			t.Fatalf("%s[%d] failed: want error %v, got %v", t.Name(), idx, strukt.Err, err)
+		}
+	}
+}
+
+func TestListFlag(t *testing.T) {
+	for idx, strukt := range []struct {
+		Sets []string
+		Want listFlag
+	}{
+		{[]string{`iso`}, listFlag{`iso`}},
+		{[]string{`itu-t, iso`, `joint-iso-itu-t`}, listFlag{`itu-t`, `iso`, `joint-iso-itu-t`}},
+		{[]string{` , ,iso,`}, listFlag{`iso`}},
+		{[]string{``}, nil},
+	} {
+		var got listFlag
+		for _, val := range strukt.Sets {
+			if err := got.Set(val); err != nil {
+				t.Fatalf("%s[%d] failed: %v", t.Name(), idx, err)
+			}
+		}
+
+		if !reflect.DeepEqual(got, strukt.Want) {
+			t.Fatalf("%s[%d] failed:\n\twant: %#v\n\tgot:  %#v", t.Name(), idx, strukt.Want, got)
+		} else if got.String() != strings.Join(strukt.Want, `,`) {
+			t.Fatalf("%s[%d] failed: unexpected string value %q", t.Name(), idx, got.String())
+		}
+	}
+}
+
+func TestSourceFlags(t *testing.T) {
+	for idx, strukt := range []struct {
+		Args []string
+		Keys []string
+		OK   bool
+	}{
+		{[]string{}, nil, true},
+		{[]string{`-pen`, `pen.txt`, `-csv`, `custom.csv`}, []string{`csvfile`, `penfile`}, true},
+		{[]string{`-prime`, `0,iso`, `-prime`, `joint-iso-itu-t`}, nil, true},
+		{[]string{`-no-prime`, `-prime`, `bogus`}, nil, true},
+		{[]string{`-prime`, `bogus`}, nil, false},
+		{[]string{`-model`, `4D`}, nil, false},
+		{[]string{`-policy`, `combined`, `-model`, `2D`}, nil, true},
+		{[]string{`-registration-base`, `not a DN`}, nil, false},
+	} {
+		var src sourceFlags
+		fs := flag.NewFlagSet(`test`, flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		src.register(fs)
+		if err := parse(fs, strukt.Args, 0); err != nil {
+			t.Fatalf("%s[%d] failed: %v", t.Name(), idx, err)
+		}
+
+		var keys []string
+		for key := range src.list() {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		if !reflect.DeepEqual(keys, strukt.Keys) {
+			t.Fatalf("%s[%d] failed: want sources %v, got %v", t.Name(), idx, strukt.Keys, keys)
+		}
+
+		dit, err := src.new()
+		if err == nil {
+			err = src.primeRoots(dit)
+		}
+
+		if ok := err == nil; ok != strukt.OK {
+			t.Fatalf("%s[%d] failed: want success %t, got error %v", t.Name(), idx, strukt.OK, err)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	for _, strukt := range []struct {
+		Args   []string
+		Status int
+		Stdout string
+		Stderr string
+	}{
+		{nil, 2, ``, `Usage: radit <command>`},
+		{[]string{`help`}, 0, `Commands:`, ``},
+		{[]string{`bogus`}, 2, ``, `unknown command "bogus"`},
+		{[]string{`build`, `-bogus`}, 2, ``, `Usage: radit build`},
+		{[]string{`tree`, `-h`}, 0, ``, `Usage: radit tree [flags] <oid>`},
+		{[]string{`validate`}, 2, ``, `no sources specified`},
+		{
+			[]string{`lookup`, `-pen`, testdata(`pen.txt`), `1.3.6.1.4.1.1`},
+			0, "dotNotation:       1.3.6.1.4.1.1\n", ``,
+		},
+		{
+			[]string{`lookup`, `-pen`, testdata(`pen.txt`), `1.3.6.1.4.1.999999999`},
+			1, ``, `radit: `,
+		},
+		{
+			[]string{`tree`, `-pen`, testdata(`pen.txt`), `-max-children`, `2`, `1.3.6.1.4.1`},
+			0, " more\n", ``,
+		},
+		{
+			[]string{`export`, `-csv`, testdata(`custom.csv`), `-format`, `csv`,
+				`-base`, `1.3.6.1.4.1.56521.999`, `-columns`, `dotNotation,identifier`},
+			0, "1.3.6.1.4.1.56521.999,testArc\n", ``,
+		},
+		{
+			[]string{`export`, `-format`, `bogus`},
+			1, ``, `unknown export format 'bogus'`,
+		},
+		{
+			[]string{`build`, `-pen`, testdata(`pen.txt`)},
+			0, "dn: ", ``,
+		},
+		{
+			[]string{`validate`, `-pen`, testdata(`pen.txt`)},
+			0, `penfile: `, ``,
+		},
+		{
+			[]string{`diff`, testdata(`custom.csv`), testdata(`registrants.jsonl`)},
+			0, "changetype: add\n", ``,
+		},
+		{
+			[]string{`diff`, testdata(`custom.csv`)},
+			2, ``, `expected 2 argument(s), got 1`,
+		},
+	} {
+		var stdout, stderr bytes.Buffer
+		status := run(strukt.Args, &stdout, &stderr)
+		if status != strukt.Status {
+			t.Fatalf("%s failed: %v: want status %d, got %d:\n%s",
+				t.Name(), strukt.Args, strukt.Status, status, stderr.String())
+		} else if !strings.Contains(stdout.String(), strukt.Stdout) {
+			t.Fatalf("%s failed: %v: standard output lacks %q:\n%s",
+				t.Name(), strukt.Args, strukt.Stdout, stdout.String())
+		} else if !strings.Contains(stderr.String(), strukt.Stderr) {
+			t.Fatalf("%s failed: %v: standard error lacks %q:\n%s",
+				t.Name(), strukt.Args, strukt.Stderr, stderr.String())
+		}
+	}
+}
+
+func TestRun_output(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `ra.ldif`)
+
+	var stdout, stderr bytes.Buffer
+	if status := run([]string{`build`, `-pen`, testdata(`pen.txt`), `-o`, path},
+		&stdout, &stderr); status != 0 {
+		t.Fatalf("%s failed: want status 0, got %d:\n%s", t.Name(), status, stderr.String())
+	} else if stdout.Len() > 0 {
+		t.Fatalf("%s failed: unexpected standard output:\n%s", t.Name(), stdout.String())
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if !bytes.Contains(content, []byte(`dn: `)) {
+		t.Fatalf("%s failed: unexpected output file content", t.Name())
+	}
+
+	// A dump differs in no way from itself.
+	if status := run([]string{`diff`, path, path}, &stdout, &stderr); status != 0 {
+		t.Fatalf("%s failed: want status 0, got %d:\n%s", t.Name(), status, stderr.String())
+	} else if stdout.Len() > 0 {
+		t.Fatalf("%s failed: unexpected change records:\n%s", t.Name(), stdout.String())
+	}
+
+	// The dump must be importable by the library alike.
+	var prof profileFlags
+	var dit *radit.RADIT
+	if dit, err = prof.new(); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if err = dit.Import(radit.ImportList{`ldiffile`: path}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if _, err = dit.Lookup(`1.3.6.1.4.1.1`); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+}
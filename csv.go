@@ -0,0 +1,195 @@
+package radit
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/oid-directory/go-radir"
+	"github.com/oid-directory/go-radit/internal/common"
+)
+
+/*
+CSV column names, for use within [CSVOptions]. The dotNotation, identifier,
+description, status, range, uri, info, o, cn and email columns are also
+understood by the "csvfile" importer, such that the output of [RADIT.WriteCSV]
+may be imported elsewhere. Multi-valued columns are split upon '|', the o, cn
+and email columns yielding one registrant per value. All other columns are
+ignored by the importer.
+*/
+const (
+	CSVDotNotation       = common.CSVDotNotation
+	CSVIdentifier        = common.CSVIdentifier
+	CSVNameAndNumberForm = common.CSVNameAndNumberForm
+	CSVASN1Notation      = common.CSVASN1Notation
+	CSVIRI               = common.CSVIRI
+	CSVUnicodeValue      = common.CSVUnicodeValue
+	CSVDescription       = common.CSVDescription
+	CSVStatus            = common.CSVStatus
+	CSVRange             = common.CSVRange
+	CSVURI               = common.CSVURI
+	CSVInfo              = common.CSVInfo
+	CSVO                 = common.CSVO
+	CSVCN                = common.CSVCN
+	CSVEmail             = common.CSVEmail
+	CSVSource            = common.CSVSource
+)
+
+/*
+DefaultCSVColumns contains the columns written by [RADIT.WriteCSV] when
+none are specified.
+*/
+var DefaultCSVColumns = []string{
+	CSVDotNotation,
+	CSVIdentifier,
+	CSVNameAndNumberForm,
+	CSVASN1Notation,
+	CSVIRI,
+	CSVDescription,
+	CSVStatus,
+	CSVRange,
+	CSVO,
+	CSVCN,
+	CSVEmail,
+	CSVSource,
+}
+
+/*
+CSVOptions contains the settings which govern the content produced by the
+[RADIT.WriteCSV] method.
+*/
+type CSVOptions struct {
+	// WriteOptions governs the selection and order of registrations,
+	// as described for [RADIT.WriteLDIF]. Subentries and Registrants
+	// have no effect; registrant columns are written when requested.
+	WriteOptions
+
+	// Columns contains the names of the columns to write, in order.
+	// Column names are matched without regard to case. An empty slice
+	// selects [DefaultCSVColumns].
+	Columns []string
+
+	// Comma is the field delimiter, e.g.: '\t' for TSV output. A zero
+	// value selects ','.
+	Comma rune
+}
+
+/*
+csvValue returns the value of a single CSV column for the input [Entry]
+and *[radir.Registration] instances. Multiple values are delimited by '|'.
+*/
+type csvValue func(*Entry, *radir.Registration, *common.DIT) string
+
+var csvValues = map[string]csvValue{
+	CSVDotNotation: func(e *Entry, _ *radir.Registration, _ *common.DIT) string { return e.DotNotation },
+	CSVIdentifier:  func(e *Entry, _ *radir.Registration, _ *common.DIT) string { return e.Identifier },
+	CSVNameAndNumberForm: func(_ *Entry, reg *radir.Registration, _ *common.DIT) string {
+		return reg.X680().NameAndNumberForm()
+	},
+	CSVASN1Notation: func(e *Entry, _ *radir.Registration, _ *common.DIT) string { return e.ASN1Notation },
+	CSVIRI:          func(e *Entry, _ *radir.Registration, _ *common.DIT) string { return e.IRI },
+	CSVUnicodeValue: func(e *Entry, _ *radir.Registration, _ *common.DIT) string { return e.UnicodeValue },
+	CSVDescription:  func(e *Entry, _ *radir.Registration, _ *common.DIT) string { return e.Description },
+	CSVStatus:       func(e *Entry, _ *radir.Registration, _ *common.DIT) string { return e.Status },
+	CSVRange:        func(e *Entry, _ *radir.Registration, _ *common.DIT) string { return e.Range },
+	CSVURI:          func(e *Entry, _ *radir.Registration, _ *common.DIT) string { return strings.Join(e.URIs, `|`) },
+	CSVInfo:         func(e *Entry, _ *radir.Registration, _ *common.DIT) string { return strings.Join(e.Info, `|`) },
+	CSVO: func(e *Entry, _ *radir.Registration, _ *common.DIT) string {
+		return joinRegistrants(e, func(athy EntryRegistrant) string { return athy.O })
+	},
+	CSVCN: func(e *Entry, _ *radir.Registration, _ *common.DIT) string {
+		return joinRegistrants(e, func(athy EntryRegistrant) string { return athy.CN })
+	},
+	CSVEmail: func(e *Entry, _ *radir.Registration, _ *common.DIT) string {
+		return joinRegistrants(e, func(athy EntryRegistrant) string { return athy.Email })
+	},
+	CSVSource: func(_ *Entry, reg *radir.Registration, dit *common.DIT) string { return dit.Source(reg) },
+}
+
+func joinRegistrants(e *Entry, field func(EntryRegistrant) string) string {
+	vals := make([]string, len(e.Registrants))
+	for i, athy := range e.Registrants {
+		vals[i] = field(athy)
+	}
+
+	return strings.Join(vals, `|`)
+}
+
+/*
+WriteCSV writes one row per registration present within the receiver
+instance, preceded by a header row, to the input [io.Writer] instance,
+returning the number of bytes written alongside an error, if any.
+
+Rows are written in depth-first order as they are produced, rather than
+being assembled in memory. Multi-valued fields, such as the URIs of a
+registration or the organizations of multiple registrants, are delimited
+by '|'.
+
+The source column contains the source by which each registration was first
+allocated: "seed" for primed registrations, or the [ImportList] key of the
+importer, e.g.: "penfile".
+*/
+func (r *RADIT) WriteCSV(w io.Writer, opts CSVOptions) (n int64, err error) {
+	if r.IsZero() {
+		err = errors.New("RADIT instance is nil, aborting write")
+		return
+	} else if w == nil {
+		err = errors.New("io.Writer instance is nil, aborting write")
+		return
+	}
+
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = DefaultCSVColumns
+	}
+
+	header := make([]string, len(columns))
+	values := make([]csvValue, len(columns))
+	for i, col := range columns {
+		for name, funk := range csvValues {
+			if strings.EqualFold(name, strings.TrimSpace(col)) {
+				header[i], values[i] = name, funk
+				break
+			}
+		}
+
+		if values[i] == nil {
+			err = errors.New("Unknown CSV column '" + col + "', aborting write")
+			return
+		}
+	}
+
+	bases, unlock, err := r.prepare(opts.WriteOptions)
+	defer unlock()
+	if err != nil {
+		return
+	}
+
+	cnt := &countWriter{w: w}
+	cw := csv.NewWriter(cnt)
+	if opts.Comma != 0 {
+		cw.Comma = opts.Comma
+	}
+
+	entry := r.dit.EntryWriter(true)
+	err = cw.Write(header)
+	for i := 0; i < len(bases) && err == nil; i++ {
+		err = walk(bases[i], opts.WriteOptions, func(reg *radir.Registration) error {
+			e := entry(reg)
+			row := make([]string, len(values))
+			for j, funk := range values {
+				row[j] = funk(e, reg, r.dit)
+			}
+			return cw.Write(row)
+		})
+	}
+
+	cw.Flush()
+	if err == nil {
+		err = cw.Error()
+	}
+	n = cnt.n
+
+	return
+}
@@ -0,0 +1,323 @@
+package radit
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/oid-directory/go-radir"
+	"github.com/oid-directory/go-radit/internal/common"
+)
+
+/*
+DiagramColor defines the manner in which the nodes written by [RADIT.WriteDOT]
+and [RADIT.WriteMermaid] are colored.
+*/
+type DiagramColor uint8
+
+const (
+	ColorNone     DiagramColor = iota // nodes are not colored
+	ColorByStatus                     // nodes are colored by status, e.g.: OBSOLETE
+	ColorBySource                     // nodes are colored by source, e.g.: "penfile"
+)
+
+/*
+DiagramOptions contains the settings which govern the content produced by
+the [RADIT.WriteDOT] and [RADIT.WriteMermaid] methods.
+*/
+type DiagramOptions struct {
+	// WriteOptions governs the selection and order of registrations,
+	// as described for [RADIT.WriteLDIF]. Subentries and Registrants
+	// have no effect. Registrations omitted by the Filter are replaced
+	// by their children.
+	WriteOptions
+
+	// MaxChildren limits the number of children drawn beneath any one
+	// registration, the remainder being summarized by a single node.
+	// Children omitted by the Filter are not counted, whereas those
+	// drawn in their stead are. A value of zero imposes no limit.
+	MaxChildren int
+
+	// Color selects the coloring of nodes.
+	Color DiagramColor
+}
+
+/*
+diagramColors contains the fill color of each node class. Statuses other
+than those shown are assigned the "status" class.
+*/
+var diagramColors = map[string]string{
+	`obsolete`:   `#d3d3d3`,
+	`deprecated`: `#ffd27f`,
+	`status`:     `#fff3b0`,
+
+	common.SourceSeed: `#eeeeee`,
+	`smifile`:         `#cce5ff`,
+	`ldapfile`:        `#d4edda`,
+	`penfile`:         `#fff3cd`,
+	`csvfile`:         `#f8d7da`,
+	`ldiffile`:        `#e2d9f3`,
+	`jsonfile`:        `#e2d9f3`,
+}
+
+/*
+diagramNode describes a single node of a diagram. Summary nodes, which bear
+no registration, stand in for children which were not drawn.
+*/
+type diagramNode struct {
+	id      string
+	label   string
+	class   string
+	summary bool
+}
+
+/*
+diagram contains the nodes and edges of the selected subtrees.
+*/
+type diagram struct {
+	opts  DiagramOptions
+	dit   *common.DIT
+	nodes []diagramNode
+	edges [][2]string
+}
+
+/*
+WriteDOT writes the registrations present within the receiver instance to
+the input [io.Writer] instance as a Graphviz DOT digraph, returning the number
+of bytes written alongside an error, if any. Each node is labelled with the
+nameAndNumberForm of its registration, e.g.:
+
+	digraph radit {
+	  node [shape=box];
+	  n1_3_6_1 [label="internet(1)"];
+	  n1_3_6_1_1 [label="directory(1)"];
+	  n1_3_6_1 -> n1_3_6_1_1;
+	}
+
+A registration whose children lie beyond [WriteOptions.MaxDepth], or whose
+children exceed [DiagramOptions.MaxChildren], is given a dashed summary node
+bearing the number of children not drawn. A base should be specified for all
+but the smallest of trees.
+*/
+func (r *RADIT) WriteDOT(w io.Writer, opts DiagramOptions) (n int64, err error) {
+	return r.writeDiagram(w, opts, (*diagram).dot)
+}
+
+/*
+WriteMermaid writes the registrations present within the receiver instance to
+the input [io.Writer] instance as a Mermaid "graph TD" flowchart, returning
+the number of bytes written alongside an error, if any, e.g.:
+
+	graph TD
+	  n1_3_6_1["internet(1)"]
+	  n1_3_6_1_1["directory(1)"]
+	  n1_3_6_1 --> n1_3_6_1_1
+
+See [RADIT.WriteDOT] for details regarding labels and limits.
+*/
+func (r *RADIT) WriteMermaid(w io.Writer, opts DiagramOptions) (n int64, err error) {
+	return r.writeDiagram(w, opts, (*diagram).mermaid)
+}
+
+func (r *RADIT) writeDiagram(w io.Writer, opts DiagramOptions, render func(*diagram) string) (n int64, err error) {
+	if r.IsZero() {
+		err = errors.New("RADIT instance is nil, aborting write")
+		return
+	} else if w == nil {
+		err = errors.New("io.Writer instance is nil, aborting write")
+		return
+	} else if opts.MaxChildren < 0 || opts.MaxDepth < 0 {
+		err = errors.New("Negative diagram limit, aborting write")
+		return
+	} else if opts.Color > ColorBySource {
+		err = errors.New("Unknown diagram color, aborting write")
+		return
+	}
+
+	bases, unlock, err := r.prepare(opts.WriteOptions)
+	defer unlock()
+	if err != nil {
+		return
+	}
+
+	d := &diagram{opts: opts, dit: r.dit}
+	for _, base := range bases {
+		if omit, _ := skip(base, 0, opts.WriteOptions); omit {
+			// The children of an omitted base are drawn
+			// as roots, subject to MaxChildren.
+			d.descend(base, ``, 0)
+		} else {
+			d.collect(base, ``, 0)
+		}
+	}
+
+	cw := &countWriter{w: w}
+	_, err = io.WriteString(cw, render(d))
+	n = cw.n
+
+	return
+}
+
+/*
+collect records the node of the input registration, and the edge joining
+it to the input superior node, followed by those of its descendants. The
+registration is presumed to be visible.
+*/
+func (r *diagram) collect(reg *radir.Registration, sup string, depth int) {
+	id := diagramID(reg)
+	label := reg.X680().NameAndNumberForm()
+	if label == "" {
+		label = reg.X680().N()
+	}
+
+	r.nodes = append(r.nodes, diagramNode{id: id, label: label, class: r.class(reg)})
+	r.edge(sup, id)
+	r.descend(reg, id, depth)
+}
+
+/*
+descend records the visible children of the input registration beneath the
+input node, which is a zero string if the registration itself is omitted,
+followed by a summary of those children not drawn.
+*/
+func (r *diagram) descend(reg *radir.Registration, id string, depth int) {
+	var kids []treeNode
+	var more int
+	if _, deep := skip(reg, depth, r.opts.WriteOptions); deep {
+		more = hidden(reg, depth, r.opts.WriteOptions)
+	} else {
+		kids = children(reg, depth, r.opts.WriteOptions)
+		if max := r.opts.MaxChildren; max > 0 && len(kids) > max {
+			kids, more = kids[:max], len(kids)-max
+		}
+	}
+
+	for _, kid := range kids {
+		r.collect(kid.reg, id, kid.depth)
+	}
+
+	if more > 0 {
+		label := strconv.Itoa(more) + ` more`
+		if len(kids) == 0 {
+			label = strconv.Itoa(more) + ` children`
+		}
+		// The summary is named after the registration, which
+		// need not have been drawn.
+		sum := diagramID(reg) + `_more`
+		r.nodes = append(r.nodes, diagramNode{id: sum, label: `… ` + label, summary: true})
+		r.edge(id, sum)
+	}
+}
+
+/*
+diagramID returns the node ID of the input registration, e.g.: "n1_3_6_1".
+*/
+func diagramID(reg *radir.Registration) string {
+	return `n` + strings.ReplaceAll(reg.X680().DotNotation(), `.`, `_`)
+}
+
+func (r *diagram) edge(from, to string) {
+	if from != "" {
+		r.edges = append(r.edges, [2]string{from, to})
+	}
+}
+
+/*
+class returns the node class of the input registration, or a zero string if
+the node is not to be colored.
+*/
+func (r *diagram) class(reg *radir.Registration) (class string) {
+	switch r.opts.Color {
+	case ColorByStatus:
+		if status := strings.ToLower(reg.Supplement().Status()); status != "" && status != `current` {
+			class = `status`
+			if _, found := diagramColors[status]; found {
+				class = status
+			}
+		}
+	case ColorBySource:
+		if class = r.dit.Source(reg); diagramColors[class] == "" {
+			class = ``
+		}
+	}
+
+	return
+}
+
+func (r *diagram) dot() string {
+	var b strings.Builder
+
+	b.WriteString("digraph radit {\n  node [shape=box];\n")
+	for _, node := range r.nodes {
+		attrs := `label=` + dotQuote(node.label)
+		if node.summary {
+			attrs += ` style=dashed`
+		} else if node.class != "" {
+			attrs += ` style=filled fillcolor=` + dotQuote(diagramColors[node.class])
+		}
+		b.WriteString(`  ` + node.id + ` [` + attrs + "];\n")
+	}
+
+	for _, edge := range r.edges {
+		b.WriteString(`  ` + edge[0] + ` -> ` + edge[1] + ";\n")
+	}
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+func (r *diagram) mermaid() string {
+	var b strings.Builder
+
+	b.WriteString("graph TD\n")
+	classes := make(map[string][]string)
+	var order []string
+	for _, node := range r.nodes {
+		b.WriteString(`  ` + node.id + `["` + mermaidEscape(node.label) + "\"]\n")
+
+		class := node.class
+		if node.summary {
+			class = `summary`
+		}
+
+		if class != "" {
+			if _, found := classes[class]; !found {
+				order = append(order, class)
+			}
+			classes[class] = append(classes[class], node.id)
+		}
+	}
+
+	for _, edge := range r.edges {
+		b.WriteString(`  ` + edge[0] + ` --> ` + edge[1] + "\n")
+	}
+
+	for _, class := range order {
+		style := `stroke-dasharray:4 4`
+		if class != `summary` {
+			style = `fill:` + diagramColors[class]
+		}
+
+		// Class names are prefixed so as not to clash
+		// with Mermaid keywords, such as "default".
+		b.WriteString(`  classDef c-` + class + ` ` + style + "\n")
+		b.WriteString(`  class ` + strings.Join(classes[class], `,`) + ` c-` + class + "\n")
+	}
+
+	return b.String()
+}
+
+/*
+dotQuote returns the input value as a DOT quoted string.
+*/
+func dotQuote(in string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(in) + `"`
+}
+
+/*
+mermaidEscape escapes the input value for use within a quoted Mermaid label.
+*/
+func mermaidEscape(in string) string {
+	return strings.NewReplacer(`"`, `#quot;`, "\n", `<br>`).Replace(in)
+}
@@ -0,0 +1,498 @@
+package radit
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/oid-directory/go-radit/internal/common"
+)
+
+/*
+Diff writes the RFC 2849 change records which, when applied to a directory
+populated by the LDIF content of the old instance, render it equivalent to
+that of the new instance, to the input [io.Writer] instance. The number of
+bytes written is returned alongside an error, if any. An error is returned
+if either instance is nil.
+
+Entries are compared by DN (without regard to case) and attribute values,
+including subentries and, where applicable, registrant entries. Records are
+written in the following order, such that each may be applied in sequence:
+
+  - add and moddn, superiors first, in the order of the new instance
+  - modify, for entries whose attribute values have changed
+  - delete, for removed entries, subordinates first
+
+Registrants, whose DNs may be generated anew by each import, are first matched
+by value: a registrant present only within the new instance is given the DN of
+the one registrant present only within the old instance which bears the same
+attribute values, save those used within either RDN, and is referenced by the
+same entries. Two builds of the same sources therefore produce no records.
+
+An entry is considered renamed when it has been removed from the old instance
+and exactly one added entry bears the same values for all attribute types,
+save those used within either RDN. Only the superior-most entry of a renamed
+subtree is given a moddn record, its subordinates being renamed alongside it.
+Subordinates whose RDN has also changed are given a moddn record of their
+own, naming the entry as it appears once its superior has been renamed.
+
+Neither instance is modified, nor is either instance locked exclusively, thus
+entries are compared in the order in which they were imported rather than by
+number form.
+
+This is intended for incremental updates of a live directory following each
+refresh of the IANA registries, in lieu of a full dump via [RADIT.WriteLDIF].
+*/
+func Diff(w io.Writer, old, newer *RADIT) (n int64, err error) {
+	if old.IsZero() || newer.IsZero() {
+		err = errors.New("RADIT instance is nil, aborting diff")
+		return
+	} else if w == nil {
+		err = errors.New("io.Writer instance is nil, aborting diff")
+		return
+	}
+
+	var before, after []common.LDIFEntry
+	var oldAths, newAths map[string]bool
+	if before, oldAths, err = old.entries(); err != nil {
+		return
+	} else if after, newAths, err = newer.entries(); err != nil {
+		return
+	}
+
+	d := &differ{ldifWriter: &ldifWriter{w: w}}
+	d.diff(before, alias(before, after, oldAths, newAths))
+	n, err = d.n, d.err
+
+	return
+}
+
+/*
+entries returns the parsed LDIF content of the receiver instance, alongside
+the lowercased DNs of its registrants.
+*/
+func (r *RADIT) entries() (entries []common.LDIFEntry, aths map[string]bool, err error) {
+	var buf bytes.Buffer
+	if _, err = r.WriteLDIF(&buf, WriteOptions{
+		Subentries:  true,
+		Registrants: true,
+	}); err != nil {
+		return
+	} else if entries, err = common.ParseLDIF(&buf); err != nil {
+		return
+	}
+
+	r.dit.RLock()
+	defer r.dit.RUnlock()
+
+	aths = make(map[string]bool)
+	if r.dit.Profile().Dedicated() {
+		regs := r.dit.Registrants()
+		for i := 0; i < regs.Len(); i++ {
+			aths[strings.ToLower(regs.Index(i).DN())] = true
+		}
+	}
+
+	return
+}
+
+/*
+alias returns the input after entries, in which each registrant matched to a
+registrant of the before entries, as described for [Diff], is replaced by that
+registrant, and each reference to it rewritten accordingly. The input entries
+are not modified.
+*/
+func alias(before, after []common.LDIFEntry, oldAths, newAths map[string]bool) []common.LDIFEntry {
+	oldDN, newDN := dnSet(before), dnSet(after)
+	oldRefs, newRefs := references(before, oldAths), references(after, newAths)
+
+	sigs := make(map[string][]int)
+	for i, entry := range before {
+		if dn := strings.ToLower(entry.DN); oldAths[dn] && !newDN[dn] {
+			sig := signature(entry) + "\x02" + oldRefs[dn]
+			sigs[sig] = append(sigs[sig], i)
+		}
+	}
+
+	claimed := make(map[string]int)
+	for _, entry := range after {
+		if dn := strings.ToLower(entry.DN); newAths[dn] && !oldDN[dn] {
+			claimed[signature(entry)+"\x02"+newRefs[dn]]++
+		}
+	}
+
+	matched := make(map[string]int) // after DN -> before index
+	for _, entry := range after {
+		if dn := strings.ToLower(entry.DN); newAths[dn] && !oldDN[dn] {
+			sig := signature(entry) + "\x02" + newRefs[dn]
+			if cands := sigs[sig]; len(cands) == 1 && claimed[sig] == 1 {
+				matched[dn] = cands[0]
+			}
+		}
+	}
+
+	if len(matched) == 0 {
+		return after
+	}
+
+	aliased := make([]common.LDIFEntry, len(after))
+	for j, entry := range after {
+		if i, found := matched[strings.ToLower(entry.DN)]; found {
+			aliased[j] = before[i]
+			continue
+		}
+
+		aliased[j] = entry
+		var copied bool
+		for _, typ := range entry.Types {
+			for k, val := range entry.Attrs[typ] {
+				if i, found := matched[strings.ToLower(val)]; found {
+					if !copied {
+						aliased[j].Attrs = make(map[string][]string, len(entry.Attrs))
+						for t, vals := range entry.Attrs {
+							aliased[j].Attrs[t] = append([]string{}, vals...)
+						}
+						copied = true
+					}
+					aliased[j].Attrs[typ][k] = before[i].DN
+				}
+			}
+		}
+	}
+
+	return aliased
+}
+
+/*
+dnSet returns the lowercased DNs of the input entries.
+*/
+func dnSet(entries []common.LDIFEntry) map[string]bool {
+	dns := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		dns[strings.ToLower(entry.DN)] = true
+	}
+
+	return dns
+}
+
+/*
+references returns the lowercased DNs of the input registrants, each mapped to
+the sorted and lowercased DNs of those input entries which reference it.
+*/
+func references(entries []common.LDIFEntry, aths map[string]bool) map[string]string {
+	refs := make(map[string][]string)
+	for _, entry := range entries {
+		for _, typ := range entry.Types {
+			for _, val := range entry.Attrs[typ] {
+				if dn := strings.ToLower(val); aths[dn] {
+					refs[dn] = append(refs[dn], strings.ToLower(entry.DN))
+				}
+			}
+		}
+	}
+
+	joined := make(map[string]string, len(refs))
+	for dn, from := range refs {
+		sort.Strings(from)
+		joined[dn] = strings.Join(from, "\x00")
+	}
+
+	return joined
+}
+
+/*
+differ writes the change records which transform one set of entries into
+another.
+*/
+type differ struct {
+	*ldifWriter
+
+	before, after []common.LDIFEntry
+	superior      []int       // before index -> before index of superior, or -1
+	renamed       map[int]int // before index -> after index
+	applied       map[int]bool
+}
+
+func (r *differ) diff(before, after []common.LDIFEntry) {
+	r.before, r.after = before, after
+
+	oldDN := make(map[string]int, len(before))
+	for i, entry := range before {
+		oldDN[strings.ToLower(entry.DN)] = i
+	}
+	newDN := make(map[string]int, len(after))
+	for i, entry := range after {
+		newDN[strings.ToLower(entry.DN)] = i
+	}
+
+	r.superior = make([]int, len(before))
+	for i, entry := range before {
+		_, sup := cutDN(entry.DN)
+		if k, found := oldDN[strings.ToLower(sup)]; found && sup != "" {
+			r.superior[i] = k
+		} else {
+			r.superior[i] = -1
+		}
+	}
+
+	var added, removed []int
+	for i, entry := range after {
+		if _, found := oldDN[strings.ToLower(entry.DN)]; !found {
+			added = append(added, i)
+		}
+	}
+	for i, entry := range before {
+		if _, found := newDN[strings.ToLower(entry.DN)]; !found {
+			removed = append(removed, i)
+		}
+	}
+
+	r.renamed = renames(before, after, added, removed)
+	r.applied = make(map[int]bool, len(r.renamed))
+
+	target := make(map[int]int, len(r.renamed))
+	for i, j := range r.renamed {
+		target[j] = i
+	}
+
+	// Entries which were not recognized as renamed, but which reappear
+	// beneath a renamed superior, are renamed alongside the superior.
+	var implied []int
+	for _, i := range removed {
+		if hasKey(r.renamed, i) {
+			continue
+		} else if dn := r.expected(i); dn != before[i].DN {
+			if j, found := newDN[strings.ToLower(dn)]; found && !hasKey(target, j) {
+				r.renamed[i], target[j] = j, i
+				implied = append(implied, i)
+			}
+		}
+	}
+
+	for _, j := range added {
+		if i, found := target[j]; found {
+			r.moddn(i, j)
+		} else {
+			r.add(after[j])
+		}
+	}
+
+	for _, entry := range after {
+		if i, found := oldDN[strings.ToLower(entry.DN)]; found {
+			r.modify(before[i], entry)
+		}
+	}
+	for _, i := range implied {
+		r.modify(before[i], after[r.renamed[i]])
+	}
+
+	for k := len(removed) - 1; k >= 0; k-- {
+		if i := removed[k]; !hasKey(r.renamed, i) {
+			r.record(r.current(i), `delete`)
+			r.write("\n")
+		}
+	}
+}
+
+/*
+current returns the DN borne by the entry at the input before index once all
+renames written thus far have been applied.
+*/
+func (r *differ) current(i int) string {
+	if r.applied[i] {
+		return r.after[r.renamed[i]].DN
+	} else if k := r.superior[i]; k != -1 {
+		rdn, _ := cutDN(r.before[i].DN)
+		return rdn + `,` + r.current(k)
+	}
+
+	return r.before[i].DN
+}
+
+/*
+expected returns the DN borne by the entry at the input before index once all
+renames have been applied.
+*/
+func (r *differ) expected(i int) string {
+	if j, found := r.renamed[i]; found {
+		return r.after[j].DN
+	} else if k := r.superior[i]; k != -1 {
+		rdn, _ := cutDN(r.before[i].DN)
+		return rdn + `,` + r.expected(k)
+	}
+
+	return r.before[i].DN
+}
+
+/*
+renames returns the indices of removed entries mapped to the indices of the
+added entries to which they were renamed.
+*/
+func renames(before, after []common.LDIFEntry, added, removed []int) (renamed map[int]int) {
+	renamed = make(map[int]int)
+
+	sigs := make(map[string][]int)
+	for _, j := range added {
+		sig := signature(after[j])
+		sigs[sig] = append(sigs[sig], j)
+	}
+
+	claimed := make(map[string]int)
+	for _, i := range removed {
+		claimed[signature(before[i])]++
+	}
+
+	for _, i := range removed {
+		sig := signature(before[i])
+		if cands := sigs[sig]; len(cands) == 1 && claimed[sig] == 1 {
+			renamed[i] = cands[0]
+		}
+	}
+
+	return
+}
+
+/*
+signature returns a string value representing all attribute values of the
+input entry, save those of the attribute types used within its RDN.
+*/
+func signature(entry common.LDIFEntry) string {
+	rdn := make(map[string]bool)
+	if first, sup := cutDN(entry.DN); sup != "" {
+		for _, ava := range strings.Split(first, `+`) {
+			if typ, _, found := strings.Cut(ava, `=`); found {
+				rdn[strings.ToLower(strings.TrimSpace(typ))] = true
+			}
+		}
+	}
+
+	var parts []string
+	for _, typ := range entry.Types {
+		if ltyp := strings.ToLower(typ); !rdn[ltyp] {
+			vals := append([]string{}, entry.Attrs[typ]...)
+			sort.Strings(vals)
+			parts = append(parts, ltyp+"\x00"+strings.Join(vals, "\x00"))
+		}
+	}
+	sort.Strings(parts)
+
+	return strings.Join(parts, "\x01")
+}
+
+/*
+cutDN returns the leading RDN of the input DN alongside the DN of its
+superior. Escaped commas are honored.
+*/
+func cutDN(dn string) (rdn, sup string) {
+	rdns := common.SplitDN(dn)
+	if rdn = rdns[0]; len(rdns) > 1 {
+		sup = dn[len(rdn)+1:]
+	}
+
+	return
+}
+
+func (r *differ) record(dn, changetype string) {
+	r.write(common.LDIFLine(`dn`, dn))
+	r.write(common.LDIFLine(`changetype`, changetype))
+}
+
+func (r *differ) add(entry common.LDIFEntry) {
+	r.record(entry.DN, `add`)
+	for _, typ := range entry.Types {
+		for _, val := range entry.Attrs[typ] {
+			r.write(common.LDIFLine(typ, val))
+		}
+	}
+	r.write("\n")
+}
+
+/*
+moddn writes a moddn change record renaming the entry at the input before
+index to the DN of the entry at the input after index, unless the rename of
+a superior has already done so.
+*/
+func (r *differ) moddn(i, j int) {
+	from, to := r.current(i), r.after[j].DN
+	r.applied[i] = true
+	if strings.EqualFold(from, to) {
+		return
+	}
+
+	rdn, sup := cutDN(to)
+	r.record(from, `moddn`)
+	r.write(common.LDIFLine(`newrdn`, rdn))
+	r.write(common.LDIFLine(`deleteoldrdn`, `1`))
+
+	if _, oldSup := cutDN(from); !strings.EqualFold(oldSup, sup) {
+		r.write(common.LDIFLine(`newsuperior`, sup))
+	}
+	r.write("\n")
+}
+
+/*
+modify writes a modify change record for the input entries, which bear the
+same DN, if any of their attribute values differ.
+*/
+func (r *differ) modify(before, after common.LDIFEntry) {
+	var changes []string
+
+	for _, typ := range after.Types {
+		vals := after.Attrs[typ]
+		switch prev := before.Values(typ); {
+		case len(prev) == 0:
+			changes = append(changes, change(`add`, typ, vals))
+		case !sameValues(prev, vals):
+			changes = append(changes, change(`replace`, typ, vals))
+		}
+	}
+
+	for _, typ := range before.Types {
+		if len(after.Values(typ)) == 0 {
+			changes = append(changes, change(`delete`, typ, nil))
+		}
+	}
+
+	if len(changes) > 0 {
+		r.record(after.DN, `modify`)
+		for _, c := range changes {
+			r.write(c)
+		}
+		r.write("\n")
+	}
+}
+
+func change(op, typ string, vals []string) (c string) {
+	c = common.LDIFLine(op, typ)
+	for _, val := range vals {
+		c += common.LDIFLine(typ, val)
+	}
+	c += "-\n"
+
+	return
+}
+
+func sameValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	x := append([]string{}, a...)
+	y := append([]string{}, b...)
+	sort.Strings(x)
+	sort.Strings(y)
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasKey(m map[int]int, k int) (found bool) {
+	_, found = m[k]
+	return
+}
@@ -0,0 +1,323 @@
+package radit
+
+import (
+	"bytes"
+	"errors"
+	"go/format"
+	"go/token"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/oid-directory/go-radir"
+)
+
+/*
+GoOptions contains the settings which govern the content produced by the
+[RADIT.WriteGo] method.
+*/
+type GoOptions struct {
+	// WriteOptions governs the selection and order of registrations,
+	// as described for [RADIT.WriteLDIF]. Subentries and Registrants
+	// have no effect.
+	WriteOptions
+
+	// Package contains the name of the generated package. A zero value
+	// selects "oids".
+	Package string
+
+	// Type contains the name of the string type declared for, and used
+	// by, the generated constants. A zero value selects "OID".
+	Type string
+
+	// Prefix is prepended to the name of each base, and therefore to
+	// that of every generated constant, e.g.: "OID". The prefix must
+	// be an exported Go identifier.
+	Prefix string
+
+	// ASN1 additionally writes an [encoding/asn1.ObjectIdentifier]
+	// variable for each constant, named after the constant with an
+	// "ASN1" suffix. Constants bearing an arc which exceeds the range
+	// of a 32-bit int, such as those beneath 2.25, are given no such
+	// variable, as the generated source would not compile portably.
+	ASN1 bool
+}
+
+/*
+goConst describes a single generated constant.
+*/
+type goConst struct {
+	name string
+	dot  string
+	doc  []string
+}
+
+/*
+goWriter collects the constants of the selected subtrees.
+*/
+type goWriter struct {
+	opts   GoOptions
+	names  map[string]bool
+	consts []goConst
+}
+
+/*
+WriteGo writes a gofmt-formatted Go source file declaring a typed constant
+for each registration present within the receiver instance to the input
+[io.Writer] instance, returning the number of bytes written alongside an
+error, if any. It is recommended that a base be specified. For example:
+
+	// Example is 1.3.6.1.4.1.56521 (example).
+	Example OID = "1.3.6.1.4.1.56521"
+
+	// ExampleSchema is 1.3.6.1.4.1.56521.2 (schema).
+	ExampleSchema OID = "1.3.6.1.4.1.56521.2"
+
+Constant names are derived hierarchically, such that the name of each
+constant is that of its superior followed by its own identifier in camel
+case, or its number form if no identifier is set. Names are therefore
+stable as registrations are added elsewhere in the tree. The name of each
+base is [GoOptions.Prefix] followed by its identifier, or by "OID" and its
+dotNotation if no identifier is set.
+
+The description and URIs of each registration are written as the doc
+comment of its constant. Constants of OBSOLETE registrations are marked
+as deprecated.
+*/
+func (r *RADIT) WriteGo(w io.Writer, opts GoOptions) (n int64, err error) {
+	if r.IsZero() {
+		err = errors.New("RADIT instance is nil, aborting write")
+		return
+	} else if w == nil {
+		err = errors.New("io.Writer instance is nil, aborting write")
+		return
+	}
+
+	if opts.Package == "" {
+		opts.Package = `oids`
+	}
+	if opts.Type == "" {
+		opts.Type = `OID`
+	}
+
+	for _, ident := range []string{opts.Package, opts.Type} {
+		if !token.IsIdentifier(ident) {
+			err = errors.New("Invalid Go identifier '" + ident + "', aborting write")
+			return
+		}
+	}
+
+	if opts.Prefix != "" && !token.IsIdentifier(opts.Prefix) {
+		err = errors.New("Invalid Go identifier prefix '" + opts.Prefix + "', aborting write")
+		return
+	} else if opts.Prefix != "" && !token.IsExported(opts.Prefix) {
+		err = errors.New("Unexported Go identifier prefix '" + opts.Prefix + "', aborting write")
+		return
+	}
+
+	bases, unlock, err := r.prepare(opts.WriteOptions)
+	defer unlock()
+	if err != nil {
+		return
+	}
+
+	gw := &goWriter{opts: opts, names: map[string]bool{opts.Type: true}}
+	for _, base := range bases {
+		name := goName(base.X680().Identifier())
+		if name == "" {
+			name = `OID` + strings.ReplaceAll(base.X680().DotNotation(), `.`, `_`)
+		}
+		gw.collect(base, opts.Prefix+name, 0)
+	}
+
+	var src []byte
+	if src, err = format.Source(gw.render()); err != nil {
+		err = errors.New("Go source formatting failed: " + err.Error())
+		return
+	}
+
+	cw := &countWriter{w: w}
+	_, err = cw.Write(src)
+	n = cw.n
+
+	return
+}
+
+/*
+collect records the constant of the input registration under the input
+name, followed by those of its descendants.
+*/
+func (r *goWriter) collect(reg *radir.Registration, name string, depth int) {
+	if reg.IsZero() {
+		return
+	}
+
+	name = r.unique(name)
+
+	omit, deep := skip(reg, depth, r.opts.WriteOptions)
+	if !omit {
+		r.consts = append(r.consts, goConst{
+			name: name,
+			dot:  reg.X680().DotNotation(),
+			doc:  goDoc(reg, name),
+		})
+	}
+
+	if deep {
+		return
+	}
+
+	kids := reg.Children()
+	for i := 0; i < kids.Len(); i++ {
+		kid := kids.Index(i)
+		sub := goName(kid.X680().Identifier())
+		if sub == "" {
+			sub = kid.X680().N()
+		}
+		r.collect(kid, name+sub, depth+1)
+	}
+}
+
+/*
+unique returns the input name, or the input name suffixed with an underscore
+and a number should it already have been assigned. The return value, and its
+[encoding/asn1] counterpart if requested, are recorded as having been assigned.
+*/
+func (r *goWriter) unique(name string) string {
+	try := name
+	for i := 2; r.names[try] || r.opts.ASN1 && r.names[try+`ASN1`]; i++ {
+		try = name + `_` + strconv.Itoa(i)
+	}
+
+	r.names[try] = true
+	if r.opts.ASN1 {
+		r.names[try+`ASN1`] = true
+	}
+
+	return try
+}
+
+/*
+render returns the unformatted Go source of the collected constants.
+*/
+func (r *goWriter) render() []byte {
+	var b bytes.Buffer
+
+	var vars []goConst
+	if r.opts.ASN1 {
+		for _, c := range r.consts {
+			if goArcs(c.dot) {
+				vars = append(vars, c)
+			}
+		}
+	}
+
+	b.WriteString("// Code generated by radit; DO NOT EDIT.\n\n")
+	b.WriteString("package " + r.opts.Package + "\n\n")
+	if len(vars) > 0 {
+		b.WriteString("import \"encoding/asn1\"\n\n")
+	}
+
+	b.WriteString("// " + r.opts.Type + " is a dotted-decimal object identifier.\n")
+	b.WriteString("type " + r.opts.Type + " string\n\n")
+
+	b.WriteString("const (\n")
+	for i, c := range r.consts {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		for _, line := range c.doc {
+			b.WriteString(strings.TrimRight("// "+line, ` `) + "\n")
+		}
+		b.WriteString(c.name + " " + r.opts.Type + " = " + strconv.Quote(c.dot) + "\n")
+	}
+	b.WriteString(")\n")
+
+	if len(vars) > 0 {
+		b.WriteString("\nvar (\n")
+		for _, c := range vars {
+			b.WriteString(c.name + "ASN1 = asn1.ObjectIdentifier{" +
+				strings.ReplaceAll(c.dot, `.`, `, `) + "}\n")
+		}
+		b.WriteString(")\n")
+	}
+
+	return b.Bytes()
+}
+
+/*
+goArcs returns a Boolean value indicative of every arc of the input dotNotation
+falling within the range of a 32-bit int, and thus of its being expressible as
+an [encoding/asn1.ObjectIdentifier] literal on all platforms.
+*/
+func goArcs(dot string) bool {
+	for _, arc := range strings.Split(dot, `.`) {
+		if _, err := strconv.ParseInt(arc, 10, 32); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+goDoc returns the lines of the doc comment of the input registration, whose
+constant bears the input name.
+*/
+func goDoc(reg *radir.Registration, name string) (doc []string) {
+	x680, sup := reg.X680(), reg.Supplement()
+
+	line := name + " is " + x680.DotNotation()
+	if id := x680.Identifier(); id != "" {
+		line += " (" + id + ")"
+	}
+	doc = append(doc, line+".")
+
+	if desc := strings.TrimSpace(reg.Description()); desc != "" {
+		doc = append(doc, "")
+		doc = append(doc, strings.Split(desc, "\n")...)
+	}
+
+	if uris := sup.URI(); len(uris) > 0 {
+		doc = append(doc, "")
+		for _, uri := range uris {
+			doc = append(doc, "See "+uri)
+		}
+	}
+
+	if strings.EqualFold(sup.Status(), `OBSOLETE`) {
+		doc = append(doc, "", "Deprecated: this registration is OBSOLETE.")
+	}
+
+	return
+}
+
+/*
+goName returns the input identifier in upper camel case, e.g.: "OidDirectory"
+for "oid-directory", or a zero string if no exported Go identifier results.
+*/
+func goName(id string) string {
+	var b strings.Builder
+
+	upper := true
+	for _, c := range id {
+		switch {
+		case unicode.IsLetter(c) || unicode.IsDigit(c) && b.Len() > 0:
+			if upper {
+				c = unicode.ToUpper(c)
+			}
+			b.WriteRune(c)
+			upper = false
+		default:
+			upper = true
+		}
+	}
+
+	name := b.String()
+	if !token.IsExported(name) {
+		name = ""
+	}
+
+	return name
+}
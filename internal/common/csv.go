@@ -0,0 +1,439 @@
+package common
+
+/*
+csv.go handles the bulk-loading of custom registrations and registrants
+from Comma-Separated Value sources.
+*/
+
+import (
+	"encoding/csv"
+	"io"
+
+	"github.com/oid-directory/go-radir"
+)
+
+/*
+CSV column names, which must appear within the header (first) row of any
+CSV source. Column names are matched without regard to case, may appear
+in any order and unrecognized columns are ignored.
+
+Registration rows require the "dotNotation" column. Registrant rows, which
+are only meaningful under the terms of the "Dedicated Registrants Policy",
+make use of the "cn", "o", "email" and "description" columns alone.
+
+Within registration rows, the "cn", "o" and "email" columns may describe
+several registrants, their values being delimited by '|' in the same order
+within each column, e.g.: "Acme|Initech" and "|ops@initech.example" describe
+two registrants, the first of which bears no email address.
+*/
+const (
+	CSVDotNotation = `dotNotation` // e.g.: 1.3.6.1.4.1.56521.999
+	CSVIdentifier  = `identifier`  // X.680 name form, e.g.: example
+	CSVDescription = `description` // free-form description
+	CSVCN          = `cn`          // registrant common name, one per registrant
+	CSVO           = `o`           // registrant organization, one per registrant
+	CSVEmail       = `email`       // registrant email address, one per registrant
+	CSVStatus      = `status`      // e.g.: OBSOLETE
+	CSVRange       = `range`       // range terminus, or -1 for infinity
+	CSVURI         = `uri`         // "URI [label]", multiple values delimited by '|'
+	CSVInfo        = `info`        // multiple values delimited by '|'
+)
+
+/*
+Additional CSV column names, which are produced by the CSV exporter but are
+ignored by [DIT.LoadCSV].
+*/
+const (
+	CSVNameAndNumberForm = `nameAndNumberForm` // e.g.: example(999)
+	CSVASN1Notation      = `asn1Notation`      // e.g.: {iso(1) ... example(999)}
+	CSVIRI               = `iri`               // e.g.: /ISO/Identified-Organization/...
+	CSVUnicodeValue      = `unicodeValue`      // X.660 unicode label
+	CSVSource            = `source`            // e.g.: seed, smifile, penfile
+)
+
+/*
+csvColumns maps the lower-case form of each supported column name to the
+column index found within the header row.
+*/
+type csvColumns map[string]int
+
+func newCSVColumns(header []string) (cols csvColumns) {
+	cols = make(csvColumns, len(header))
+	for idx, name := range header {
+		if name = lc(trimS(name)); name != "" {
+			if _, dupe := cols[name]; !dupe {
+				cols[name] = idx
+			}
+		}
+	}
+
+	return
+}
+
+/*
+value returns the trimmed value of the named column within row, or a zero
+string if the column is either undefined or absent from row.
+*/
+func (r csvColumns) value(row []string, name string) (val string) {
+	if idx, found := r[lc(name)]; found && idx < len(row) {
+		val = trimS(row[idx])
+	}
+
+	return
+}
+
+/*
+LoadCSV returns an error following an attempt to process the input
+*[csv.Reader] instance, the first row of which MUST be a header row
+bearing the column names described above.
+
+The input closure determines the nature of each subsequent row, and
+receives the resulting instances:
+
+  - *[radir.Registrations]: each row describes a registration, which is
+    allocated beneath the appropriate root by way of its dotNotation
+  - *[radir.Registrants]: each row describes a registrant, which is also
+    stored within the receiver instance
+*/
+func (r *DIT) LoadCSV(reader *csv.Reader, closure func() any) (err error) {
+	if r.IsZero() {
+		err = mkerr("DIT is nil")
+		return
+	} else if reader == nil {
+		err = mkerr("CSV reader is nil")
+		return
+	} else if closure == nil {
+		err = mkerr("closure is nil")
+		return
+	}
+
+	var header []string
+	if header, err = reader.Read(); err != nil {
+		if err == eof {
+			err = mkerr("CSV content is empty; header row required")
+		}
+		return
+	}
+
+	var load func([]string) error
+	if load, err = r.csvLoader(newCSVColumns(header), closure); err != nil {
+		return
+	}
+
+	for {
+		var row []string
+		if row, err = reader.Read(); err != nil {
+			if err == eof {
+				err = nil
+			}
+			break
+		}
+
+		if err = load(row); err != nil {
+			line, _ := reader.FieldPos(0)
+			err = csvLineErr(line, err)
+			break
+		}
+	}
+
+	return
+}
+
+/*
+csvLoader returns the closure used to load each row beneath the specified
+columns, as determined by the value returned by the input closure.
+*/
+func (r *DIT) csvLoader(cols csvColumns, closure func() any) (load func([]string) error, err error) {
+	switch tv := closure().(type) {
+	case *radir.Registrations:
+		if _, found := cols[lc(CSVDotNotation)]; !found {
+			err = mkerr("CSV header lacks required " + CSVDotNotation + " column")
+			return
+		}
+		load = func(row []string) (err error) {
+			var reg *radir.Registration
+			if reg, err = r.loadCSVRegistration(cols, row); err == nil {
+				tv.Push(reg)
+			}
+			return
+		}
+	case *radir.Registrants:
+		if !r.profile.Dedicated() {
+			err = mkerr("Registrant rows require the Dedicated Registrants Policy")
+			return
+		}
+		load = func(row []string) (err error) {
+			var athy *radir.Registrant
+			if athy, err = r.loadCSVRegistrant(cols, row); err == nil {
+				tv.Push(athy)
+			}
+			return
+		}
+	default:
+		err = mkerr("Return value is neither *radir.Registrations nor *radir.Registrants")
+	}
+
+	return
+}
+
+func csvLineErr(line int, err error) error {
+	return mkerr("CSV line " + itoa(line) + ": " + err.Error())
+}
+
+/*
+LoadCSVRegistry returns an error following an attempt to load the registrations
+described within the CSV content supplied by the input [io.Reader] instance.
+See [DIT.LoadCSV] for details regarding the expected column layout.
+*/
+func LoadCSVRegistry(r *DIT, src io.Reader) error {
+	if r.IsZero() || src == nil {
+		return mkerr("DIT or CSV source is nil")
+	}
+
+	return r.LoadCSV(newCSVReader(src), newCSVRegistrations)
+}
+
+/*
+ParseCSVRegistry returns an [Applier] alongside an error following an attempt
+to read all rows of the CSV content supplied by the input [io.Reader] instance.
+The rows are loaded, as described for [LoadCSVRegistry], only once the return
+[Applier] is called.
+*/
+func ParseCSVRegistry(src io.Reader, _ ImportOptions) (apply Applier, err error) {
+	if src == nil {
+		err = mkerr("CSV source is nil")
+		return
+	}
+
+	reader := newCSVReader(src)
+
+	var header []string
+	if header, err = reader.Read(); err != nil {
+		if err == eof {
+			err = mkerr("CSV content is empty; header row required")
+		}
+		return
+	}
+
+	var rows [][]string
+	var lines []int
+	for {
+		var row []string
+		if row, err = reader.Read(); err != nil {
+			if err == eof {
+				err = nil
+			}
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, row)
+		lines = append(lines, line)
+	}
+
+	if err != nil {
+		return
+	}
+
+	apply = func(r *DIT) (err error) {
+		if r.IsZero() {
+			return mkerr("DIT is nil")
+		}
+
+		var load func([]string) error
+		if load, err = r.csvLoader(newCSVColumns(header), newCSVRegistrations); err != nil {
+			return
+		}
+
+		for i := 0; i < len(rows) && err == nil; i++ {
+			if err = load(rows[i]); err != nil {
+				err = csvLineErr(lines[i], err)
+			}
+		}
+
+		return
+	}
+
+	return
+}
+
+func newCSVReader(src io.Reader) (reader *csv.Reader) {
+	reader = csv.NewReader(src)
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	return
+}
+
+func newCSVRegistrations() any {
+	regs := make(radir.Registrations, 0)
+	return &regs
+}
+
+func (r *DIT) loadCSVRegistration(cols csvColumns, row []string) (reg *radir.Registration, err error) {
+	e := &Entry{
+		DotNotation: cols.value(row, CSVDotNotation),
+		Identifier:  cols.value(row, CSVIdentifier),
+		Description: cols.value(row, CSVDescription),
+		Status:      cols.value(row, CSVStatus),
+		Range:       cols.value(row, CSVRange),
+		URIs:        split(cols.value(row, CSVURI), `|`),
+		Info:        split(cols.value(row, CSVInfo), `|`),
+	}
+
+	// Registrant columns are delimited in parallel.
+	o := split(cols.value(row, CSVO), `|`)
+	cn := split(cols.value(row, CSVCN), `|`)
+	email := split(cols.value(row, CSVEmail), `|`)
+	for i := 0; i < len(o) || i < len(cn) || i < len(email); i++ {
+		e.Registrants = append(e.Registrants, EntryRegistrant{
+			O:     nth(o, i),
+			CN:    nth(cn, i),
+			Email: nth(email, i),
+		})
+	}
+
+	return r.ApplyEntry(e)
+}
+
+/*
+nth returns the input slice value at the input index, or a zero string if
+out of range.
+*/
+func nth(vals []string, idx int) (val string) {
+	if idx < len(vals) {
+		val = vals[idx]
+	}
+
+	return
+}
+
+func (r *DIT) loadCSVRegistrant(cols csvColumns, row []string) (athy *radir.Registrant, err error) {
+	er := EntryRegistrant{
+		O:     trimS(cols.value(row, CSVO)),
+		CN:    trimS(cols.value(row, CSVCN)),
+		Email: trimS(cols.value(row, CSVEmail)),
+	}
+	desc := cols.value(row, CSVDescription)
+
+	// Reuse any registrant already bearing the same values,
+	// such that the same rows may be imported more than once.
+	if athy = r.registrantFor(er); !er.IsZero() && !athy.IsZero() {
+		if desc != "" && athy.Description() == "" {
+			err = athy.SetDescription(desc)
+		}
+		return
+	}
+
+	athy = r.profile.NewRegistrant()
+	athy.SetDN(radir.RegistrantDNGenerator)
+
+	for _, strukt := range []struct {
+		Field string
+		Func  func(...any) error
+	}{
+		{er.O, athy.CurrentAuthority().SetO},
+		{er.CN, athy.CurrentAuthority().SetCN},
+		{er.Email, athy.CurrentAuthority().SetEmail},
+		{desc, athy.SetDescription},
+	} {
+		if strukt.Field != "" {
+			if err = strukt.Func(strukt.Field); err != nil {
+				return
+			}
+		}
+	}
+
+	r.aths.Push(athy)
+
+	return
+}
+
+/*
+AttachRegistrant returns an error following an attempt to associate the
+input registrant with the input *[radir.Registration] instance.
+
+Under the terms of the "Dedicated Registrants Policy", the registrant bearing
+the DN of the input registrant is referenced, and is created if not found. If
+no DN is specified, the registrant bearing the same organization, common name
+and email address is referenced instead, and is created, bearing a generated
+DN, if not found. Under the terms of the "Combined Registrants Policy", the
+details are written to the registration directly.
+
+Values already present are not written again, such that a registrant may be
+attached more than once. Nothing is done if the input registrant is zero.
+*/
+func (r *DIT) AttachRegistrant(reg *radir.Registration, er EntryRegistrant) (err error) {
+	er = EntryRegistrant{
+		DN:          trimS(er.DN),
+		CN:          trimS(er.CN),
+		O:           trimS(er.O),
+		Email:       trimS(er.Email),
+		Description: trimS(er.Description),
+	}
+	if er.IsZero() {
+		return
+	}
+	dn, desc := er.DN, er.Description
+
+	var ca *radir.CurrentAuthority
+	if r.profile.Dedicated() {
+		athy := r.registrantFor(er)
+		if athy.IsZero() {
+			athy = r.profile.NewRegistrant()
+			if dn != "" {
+				athy.SetDN(dn)
+			} else {
+				athy.SetDN(radir.RegistrantDNGenerator)
+			}
+			r.aths.Push(athy)
+		}
+
+		if desc != "" && athy.Description() == "" {
+			if err = athy.SetDescription(desc); err != nil {
+				return
+			}
+		}
+
+		if !hasValue(reg.X660().CurrentAuthorities(), athy.DN()) {
+			reg.X660().SetCurrentAuthorities(athy.DN())
+		}
+		ca = athy.CurrentAuthority()
+	} else if r.profile.Combined() {
+		ca = reg.X660().CombinedCurrentAuthority()
+	} else {
+		return
+	}
+
+	for _, strukt := range []struct {
+		Field string
+		Value string
+		Func  func(...any) error
+	}{
+		{er.O, ca.O(), ca.SetO},
+		{er.CN, ca.CN(), ca.SetCN},
+		{er.Email, ca.Email(), ca.SetEmail},
+	} {
+		if strukt.Field != "" && strukt.Field != strukt.Value {
+			if err = strukt.Func(strukt.Field); err != nil {
+				break
+			}
+		}
+	}
+
+	return
+}
+
+/*
+setIdentifier assigns the input identifier to reg, updating its name and
+number form and, when the parent notation is known, its ASN.1 notation.
+*/
+func setIdentifier(reg *radir.Registration, id string) {
+	x680 := reg.X680()
+	n := x680.N()
+	x680.SetIdentifier(id)
+	x680.SetNameAndNumberForm(id + `(` + n + `)`)
+
+	if anot := x680.ASN1Notation(); hasSfx(anot, ` `+n+`}`) {
+		x680.SetASN1Notation(anot[:len(anot)-len(n)-2] + ` ` + id + `(` + n + `)}`)
+	}
+}
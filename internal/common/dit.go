@@ -1,8 +1,7 @@
 package common
 
 import (
-	"encoding/csv"
-	"errors"
+	"sync"
 
 	"github.com/oid-directory/go-radir"
 )
@@ -28,12 +27,28 @@ Generally, the purpose of instances of this type is for temporary assembly
 of DIT content derived from the various sources supported by this package,
 but could conceivably be used as a replacement for a directory information
 tree when a real one is not available.
+
+The methods of this type do not lock the receiver. Callers which share an
+instance between goroutines must hold the exclusive lock, by way of [DIT.Lock],
+while modifying the tree (e.g.: priming, importing or sorting), and at least
+the shared lock, by way of [DIT.RLock], while reading it.
 */
 type DIT struct {
+	mu      sync.RWMutex
 	tree    OIDTree
 	aths    *radir.Registrants
 	bsel    [2]int // base selector: [2]int{REG_BASE,ATH_BASE}
 	profile *radir.DITProfile
+	report  *ImportReport
+	options ImportOptions
+	index   *nameIndex
+	text    *textIndex
+	sources map[*radir.Registration]string
+	dots    map[string]bool // dotNotation of each registration bearing a source
+	touched []*radir.Registration
+	athDN   map[string]*radir.Registrant // see DIT.indexRegistrants
+	athID   map[string]*radir.Registrant // see DIT.indexRegistrants
+	athN    int
 }
 
 /*
@@ -41,12 +56,49 @@ NewDIT returns a freshly initialized instance of *[DIT].
 */
 func NewDIT(profile *radir.DITProfile) *DIT {
 	aths := make(radir.Registrants, 0)
-	return &DIT{
+	r := &DIT{
 		aths:    &aths,
 		profile: profile,
+		report:  NewImportReport(),
+		index:   newNameIndex(),
+		text:    newTextIndex(),
+		sources: make(map[*radir.Registration]string),
+		dots:    make(map[string]bool),
 	}
+
+	// Initialize all roots now, such that no
+	// subsequent read modifies the receiver.
+	r.tree[0] = r.newRoot(`0`, `itu-t`, `ITU-T`)
+	r.tree[1] = r.newRoot(`1`, `iso`, `ISO`)
+	r.tree[2] = r.newRoot(`2`, `joint-iso-itu-t`, `Joint-ISO-ITU-T`)
+	r.Touch(r.tree[:]...)
+	r.Commit(SourceSeed)
+
+	return r
 }
 
+/*
+Lock acquires the exclusive lock of the receiver instance, blocking until
+all readers and any other writer have released it.
+*/
+func (r *DIT) Lock() { r.mu.Lock() }
+
+/*
+Unlock releases the exclusive lock acquired by [DIT.Lock].
+*/
+func (r *DIT) Unlock() { r.mu.Unlock() }
+
+/*
+RLock acquires the shared lock of the receiver instance, which may be held
+by any number of readers at once.
+*/
+func (r *DIT) RLock() { r.mu.RLock() }
+
+/*
+RUnlock releases the shared lock acquired by [DIT.RLock].
+*/
+func (r *DIT) RUnlock() { r.mu.RUnlock() }
+
 /*
 IsZero returns a Boolean value indicative of a nil receiver state.
 */
@@ -77,6 +129,38 @@ func (r *DIT) Registrants() (aths *radir.Registrants) {
 	return
 }
 
+/*
+Report returns the underlying instance of *[ImportReport], or a nil instance
+if the receiver is not yet initialized.
+*/
+func (r *DIT) Report() (report *ImportReport) {
+	if !r.IsZero() {
+		report = r.report
+	}
+
+	return
+}
+
+/*
+Options returns the [ImportOptions] in force for the receiver instance.
+*/
+func (r *DIT) Options() (opts ImportOptions) {
+	if !r.IsZero() {
+		opts = r.options
+	}
+
+	return
+}
+
+/*
+SetOptions assigns the input [ImportOptions] to the receiver instance.
+*/
+func (r *DIT) SetOptions(opts ImportOptions) {
+	if !r.IsZero() {
+		r.options = opts
+	}
+}
+
 /*
 Tree returns the underlying instance of [OIDTree].
 */
@@ -108,61 +192,39 @@ func (r *DIT) LDIF() (l string) {
 }
 
 /*
-ITUT returns the ITU-T *[Registration] instance. If the instance is nil,
-it is initialized and stored prior to return.
+ITUT returns the ITU-T *[Registration] instance.
 */
-func (r *DIT) ITUT() (root *radir.Registration) {
-	if root = r.tree[0]; root.IsZero() {
-		r.tree[0] = r.profile.NewRegistration(true)
-		r.tree[0].X680().SetN(`0`)
-		r.tree[0].X680().SetIdentifier(`itu-t`)
-		r.tree[0].X680().SetIRI(`/ITU-T`)
-		r.tree[0].X680().SetASN1Notation(`{itu-t(0)}`)
-		r.tree[0].X660().SetUnicodeValue(`ITU-T`)
-		r.tree[0].X680().SetNameAndNumberForm(`itu-t(0)`)
-		r.tree[0].SetDN(`n=0,` + r.profile.RegistrationBase())
-		root = r.tree[0]
-	}
-
-	return
+func (r *DIT) ITUT() *radir.Registration {
+	return r.Root(0)
 }
 
 /*
-ISO returns the ISO *[Registration] instance. If the instance is nil, it
-is initialized and stored prior to return.
+ISO returns the ISO *[Registration] instance.
 */
-func (r *DIT) ISO() (root *radir.Registration) {
-	if root = r.tree[1]; root.IsZero() {
-		r.tree[1] = r.profile.NewRegistration(true)
-		r.tree[1].X680().SetN(`1`)
-		r.tree[1].X680().SetIdentifier(`iso`)
-		r.tree[1].X680().SetIRI(`/ISO`)
-		r.tree[1].X680().SetASN1Notation(`{iso(1)}`)
-		r.tree[1].X660().SetUnicodeValue(`ISO`)
-		r.tree[1].X680().SetNameAndNumberForm(`iso(1)`)
-		r.tree[1].SetDN(`n=1,` + r.profile.RegistrationBase())
-		root = r.tree[1]
-	}
+func (r *DIT) ISO() *radir.Registration {
+	return r.Root(1)
+}
 
-	return
+/*
+JointISOITUT returns the Joint-ISO-ITU-T *[Registration] instance.
+*/
+func (r *DIT) JointISOITUT() *radir.Registration {
+	return r.Root(2)
 }
 
 /*
-JointISOITUT returns the Joint-ISO-ITU-T *[Registration] instance. If
-the instance is nil, it is initialized and stored prior to return.
+newRoot returns a freshly initialized root *[Registration] instance bearing
+the input number form, identifier and unicode value.
 */
-func (r *DIT) JointISOITUT() (root *radir.Registration) {
-	if root = r.tree[2]; root.IsZero() {
-		r.tree[2] = r.profile.NewRegistration(true)
-		r.tree[2].X680().SetN(`2`)
-		r.tree[2].X680().SetIdentifier(`joint-iso-itu-t`)
-		r.tree[2].X680().SetIRI(`/Joint-ISO-ITU-T`)
-		r.tree[2].X680().SetASN1Notation(`{joint-iso-itu-t(2)}`)
-		r.tree[2].X660().SetUnicodeValue(`Joint-ISO-ITU-T`)
-		r.tree[2].X680().SetNameAndNumberForm(`joint-iso-itu-t(2)`)
-		r.tree[2].SetDN(`n=2,` + r.profile.RegistrationBase())
-		root = r.tree[2]
-	}
+func (r *DIT) newRoot(n, id, uval string) (root *radir.Registration) {
+	root = r.profile.NewRegistration(true)
+	root.X680().SetN(n)
+	root.X680().SetIdentifier(id)
+	root.X680().SetIRI(`/` + uval)
+	root.X680().SetASN1Notation(`{` + id + `(` + n + `)}`)
+	root.X660().SetUnicodeValue(uval)
+	root.X680().SetNameAndNumberForm(id + `(` + n + `)`)
+	root.SetDN(`n=` + n + `,` + r.profile.RegistrationBase())
 
 	return
 }
@@ -173,19 +235,45 @@ integer value, which must be 0, 1 or 2. A nil instance is returned if the
 input value is anything other than those values.  This method is merely
 a convenient programmatic alternative to explicit calls of the named root
 context (e.g.: input of 1 equals [DIT.ISO] call).
+
+All three roots are initialized by [NewDIT], thus this method never modifies
+the receiver and is safe for concurrent use alongside other readers.
 */
 func (r *DIT) Root(n int) (root *radir.Registration) {
-	if 0 <= n && n <= 2 {
-		funk := []func() *radir.Registration{
-			r.ITUT,
-			r.ISO,
-			r.JointISOITUT,
-		}
+	if !r.IsZero() && 0 <= n && n <= 2 {
+		root = r.tree[n]
+	}
+
+	return
+}
+
+/*
+Resolve returns the *[radir.Registration] instance identified by the input
+string value, which may be a root name ("itu-t", "iso" or "joint-iso-itu-t"),
+a root number form ("0", "1" or "2") or the dotNotation of any registration
+present within the receiver instance. A nil instance is returned if no such
+registration exists.
+*/
+func (r *DIT) Resolve(base string) (reg *radir.Registration) {
+	if r.IsZero() {
+		return
+	}
 
-		if r.tree[n].IsZero() {
-			r.tree[n] = funk[n]()
+	switch lc(base) {
+	case `itu-t`, `0`:
+		reg = r.ITUT()
+	case `iso`, `1`:
+		reg = r.ISO()
+	case `joint-iso-itu-t`, `2`:
+		reg = r.JointISOITUT()
+	default:
+		if sp := split(base, `.`); len(sp) > 1 && len(sp[0]) == 1 {
+			if n, err := atoi(sp[0]); err == nil {
+				if root := r.Root(n); !root.IsZero() {
+					reg = root.Walk(base)
+				}
+			}
 		}
-		root = r.tree[n]
 	}
 
 	return
@@ -205,65 +293,104 @@ func (r *DIT) Prime(n int, nodes ...string) {
 	if 0 <= n && n <= 2 {
 		root := r.Root(n)
 		for _, node := range nodes {
-			root.Allocate(node)
+			r.Touch(root.Allocate(node))
 		}
+		r.Commit(SourceSeed)
 	}
 }
 
 /*
-LoadCSV returns an error following an attempt to process the input *[csv.Reader]
-instance using the input closure instance. The result is
+SourceSeed is the source of all registrations allocated by [NewDIT] and
+[DIT.Prime], as opposed to those allocated by an importer.
+*/
+const SourceSeed = `seed`
 
-	a general-use method for loading Comma-Separated Value data
+/*
+Touch records the input *[radir.Registration] instances as having been
+allocated or modified, such that they are tagged and indexed by the next
+call of [DIT.Commit]. Importers call this for each registration they
+allocate or alter.
 */
-func (r *DIT) LoadCSV(reader *csv.Reader, closure func() any) (err error) {
-	if reader == nil {
-		err = errors.New("CSV reader is nil")
-		return
-	} else if closure == nil {
-		err = errors.New("closure is nil")
+func (r *DIT) Touch(regs ...*radir.Registration) {
+	if r.IsZero() {
 		return
 	}
 
-	out := closure()
-	switch out.(type) {
-	case *radir.Registrations:
-		//for i := 0; i < tv.Len(); i++ {
-		//	reg := tv.Index(i)
-		//	n, _ := reg.Root()
-		//	r.Root(n).Put
-		//}
-		if !r.IsZero() {
-			//r.Root(n).Allocate
+	for _, reg := range regs {
+		if !reg.IsZero() {
+			r.touched = append(r.touched, reg)
 		}
-	case *radir.Registrants:
-		if !r.IsZero() {
-		}
-	default:
-		err = errors.New("Return value is neither *radir.Registration nor *radir.Registrant")
 	}
-
-	return
 }
 
 /*
-Print will write the structure of the receiver instance, including all of
-its descendants, to STDOUT.
+Commit records the input source, such as "smifile", as the source of each
+registration touched since the previous call which does not yet bear a
+source, and updates the registrant, name and text indices accordingly. This
+is called following each import, such that each registration is tagged with
+the source by which it was first allocated.
+
+Superiors allocated implicitly alongside a touched registration are tagged
+and indexed as well. Registrations which were not touched are not visited,
+thus the cost of each call is proportional to the size of the import rather
+than that of the tree.
 */
-/*
-func (r Node) Print(level int) {
-	if &r == nil {
+func (r *DIT) Commit(source string) {
+	if r.IsZero() {
 		return
 	}
 
-	if !isNumber(r.Name) {
-		fmt.Printf("%s%s (%s)\n", repeat("  ", level), r.N, r.Name)
-	} else {
-		fmt.Printf("%s%s\n", repeat("  ", level), r.N)
+	regs := r.untagged()
+	for _, reg := range regs {
+		if _, found := r.sources[reg]; !found {
+			r.sources[reg] = source
+			r.dots[reg.X680().DotNotation()] = true
+		}
 	}
 
-	for _, child := range r.Children {
-		child.print(level + 1)
+	r.indexRegistrants()
+	r.index.update(regs)
+	r.text.update(regs, r)
+	r.touched = nil
+}
+
+/*
+untagged returns the touched registrations, without duplicates, followed by
+those of their superiors which do not yet bear a source.
+*/
+func (r *DIT) untagged() (regs []*radir.Registration) {
+	seen := make(map[string]bool, len(r.touched))
+	for _, reg := range r.touched {
+		if dot := reg.X680().DotNotation(); !seen[dot] {
+			seen[dot] = true
+			regs = append(regs, reg)
+		}
 	}
+
+	for i, touched := 0, len(regs); i < touched; i++ {
+		dot := regs[i].X680().DotNotation()
+		for idx := lidx(dot, `.`); idx != -1; idx = lidx(dot, `.`) {
+			if dot = dot[:idx]; seen[dot] || r.dots[dot] {
+				break
+			}
+			seen[dot] = true
+			if sup := r.Resolve(dot); !sup.IsZero() {
+				regs = append(regs, sup)
+			}
+		}
+	}
+
+	return
 }
+
+/*
+Source returns the source by which the input *[radir.Registration] was first
+allocated, such as [SourceSeed] or "penfile", or a zero string if unknown.
 */
+func (r *DIT) Source(reg *radir.Registration) (source string) {
+	if !r.IsZero() {
+		source = r.sources[reg]
+	}
+
+	return
+}
@@ -0,0 +1,265 @@
+package common
+
+/*
+entry.go implements a flat, format-neutral view of a registration, which is
+used by the CSV and JSON importers and exporters.
+*/
+
+import (
+	"github.com/oid-directory/go-radir"
+	"github.com/oid-directory/go-radir/oid"
+)
+
+/*
+Entry describes a single registration alongside its registrants. Children
+is only populated when describing a nested tree of registrations.
+*/
+type Entry struct {
+	DotNotation  string            `json:"dotNotation"`
+	Identifier   string            `json:"identifier,omitempty"`
+	ASN1Notation string            `json:"asn1Notation,omitempty"`
+	IRI          string            `json:"iri,omitempty"`
+	UnicodeValue string            `json:"unicodeValue,omitempty"`
+	Description  string            `json:"description,omitempty"`
+	Status       string            `json:"status,omitempty"`
+	Range        string            `json:"range,omitempty"`
+	URIs         []string          `json:"uris,omitempty"`
+	Info         []string          `json:"info,omitempty"`
+	Registrants  []EntryRegistrant `json:"registrants,omitempty"`
+	Children     []*Entry          `json:"children,omitempty"`
+}
+
+/*
+EntryRegistrant describes a single registrant of an [Entry]. The DN is only
+populated under the terms of the "Dedicated Registrants Policy", and allows
+a registrant shared by several registrations to be recognized as such.
+*/
+type EntryRegistrant struct {
+	DN          string `json:"dn,omitempty"`
+	CN          string `json:"cn,omitempty"`
+	O           string `json:"o,omitempty"`
+	Email       string `json:"email,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+/*
+IsZero returns a Boolean value indicative of a nil receiver state.
+*/
+func (r EntryRegistrant) IsZero() bool {
+	return r == EntryRegistrant{}
+}
+
+/*
+registrant returns the *[radir.Registrant] bearing the input DN, or a nil
+instance if not found. Only those registrants indexed by indexRegistrants
+are considered.
+
+As this method does not modify the receiver, it may be called by concurrent
+readers, such as [DIT.EntryWriter].
+*/
+func (r *DIT) registrant(dn string) *radir.Registrant {
+	return r.athDN[dn]
+}
+
+/*
+indexRegistrants indexes, by DN and by value, those registrants added since
+the previous call. As registrants are only ever appended, none are indexed
+anew. The receiver must be locked exclusively, and each registrant must bear
+its DN and values before the next call.
+*/
+func (r *DIT) indexRegistrants() {
+	if r.athDN == nil {
+		r.athDN = make(map[string]*radir.Registrant, r.aths.Len())
+		r.athID = make(map[string]*radir.Registrant, r.aths.Len())
+	}
+
+	for ; r.athN < r.aths.Len(); r.athN++ {
+		athy := r.aths.Index(r.athN)
+		r.athDN[athy.DN()] = athy
+
+		// The first registrant bearing any one identity wins.
+		ca := athy.CurrentAuthority()
+		if id := registrantID(ca.O(), ca.CN(), ca.Email()); id != "" && r.athID[id].IsZero() {
+			r.athID[id] = athy
+		}
+	}
+}
+
+/*
+registrantFor returns the *[radir.Registrant] bearing the DN of the input
+[EntryRegistrant] or, if no DN is specified, that bearing the same values
+for organization, common name and email address. A nil instance is returned
+if not found.
+
+Matching by value allows registrants lacking a DN, such as those of CSV rows,
+to be imported more than once without creating a new registrant each time.
+Registrants are indexed beforehand, thus the receiver must be locked
+exclusively.
+*/
+func (r *DIT) registrantFor(er EntryRegistrant) (athy *radir.Registrant) {
+	r.indexRegistrants()
+	if athy = r.registrant(er.DN); er.DN == "" {
+		athy = r.athID[registrantID(er.O, er.CN, er.Email)]
+	}
+
+	return
+}
+
+/*
+registrantID returns the identity of a registrant bearing the input values
+of organization, common name and email address, or a zero string if all are
+zero.
+*/
+func registrantID(o, cn, email string) (id string) {
+	if o != "" || cn != "" || email != "" {
+		id = o + "\x00" + cn + "\x00" + lc(email)
+	}
+
+	return
+}
+
+/*
+EntryWriter returns a closure which produces the [Entry] of any input
+*[radir.Registration] present within the receiver instance. Registrants
+are only included if the input Boolean is true.
+
+The closure should be discarded once the receiver instance is modified.
+*/
+func (r *DIT) EntryWriter(registrants bool) func(*radir.Registration) *Entry {
+	return func(reg *radir.Registration) (e *Entry) {
+		x680, x660, sup := reg.X680(), reg.X660(), reg.Supplement()
+		e = &Entry{
+			DotNotation:  x680.DotNotation(),
+			Identifier:   x680.Identifier(),
+			ASN1Notation: x680.ASN1Notation(),
+			IRI:          x680.IRI(),
+			UnicodeValue: x660.UnicodeValue(),
+			Description:  reg.Description(),
+			Status:       sup.Status(),
+			Range:        sup.Range(),
+			URIs:         sup.URI(),
+			Info:         sup.Info(),
+		}
+
+		if !registrants {
+			return
+		}
+
+		if r.profile.Dedicated() {
+			for _, dn := range x660.CurrentAuthorities() {
+				if athy := r.registrant(dn); !athy.IsZero() {
+					ca := athy.CurrentAuthority()
+					e.Registrants = append(e.Registrants, EntryRegistrant{
+						DN:          dn,
+						CN:          ca.CN(),
+						O:           ca.O(),
+						Email:       ca.Email(),
+						Description: athy.Description(),
+					})
+				}
+			}
+		} else if ca := x660.CombinedCurrentAuthority(); r.profile.Combined() && ca != nil {
+			if athy := (EntryRegistrant{CN: ca.CN(), O: ca.O(), Email: ca.Email()}); !athy.IsZero() {
+				e.Registrants = append(e.Registrants, athy)
+			}
+		}
+
+		return
+	}
+}
+
+/*
+ApplyEntry returns the *[radir.Registration] allocated for the input [Entry]
+alongside an error following an attempt to assign the values of the input
+[Entry] to it. Zero values are ignored, and Children are not considered.
+*/
+func (r *DIT) ApplyEntry(e *Entry) (reg *radir.Registration, err error) {
+	dot := trimS(e.DotNotation)
+	if !(IsNumber(dot) || oid.IsDotNotation(dot)) {
+		err = mkerr("Invalid dotNotation value '" + dot + "'")
+		return
+	}
+
+	n, _ := atoi(split(dot, `.`)[0])
+	if reg = r.Root(n); reg.IsZero() {
+		err = mkerr("Unsupported root for " + dot)
+		return
+	} else if ctns(dot, `.`) {
+		if reg = reg.Allocate(dot); reg.IsZero() {
+			err = mkerr("Allocation error: " + dot)
+			return
+		}
+	}
+
+	r.Touch(reg)
+
+	if id := trimS(e.Identifier); id != "" {
+		if !oid.IsNameForm(id) {
+			err = mkerr("Invalid identifier value '" + id + "'")
+			return
+		}
+		setIdentifier(reg, id)
+	}
+
+	for _, strukt := range []struct {
+		Field string
+		Func  func(string)
+	}{
+		{e.ASN1Notation, reg.X680().SetASN1Notation},
+		{e.IRI, reg.X680().SetIRI},
+		{e.UnicodeValue, reg.X660().SetUnicodeValue},
+		{uc(e.Status), reg.Supplement().SetStatus},
+	} {
+		if strukt.Field = trimS(strukt.Field); strukt.Field != "" {
+			strukt.Func(strukt.Field)
+		}
+	}
+
+	if desc := trimS(e.Description); desc != "" {
+		if err = reg.SetDescription(desc); err != nil {
+			return
+		}
+	}
+
+	if rng := trimS(e.Range); rng != "" {
+		if _, err = atoi(rng); err != nil {
+			err = mkerr("Invalid range value '" + rng + "'")
+			return
+		}
+		reg.Supplement().SetRange(rng)
+	}
+
+	// Multi-valued attributes are only augmented, such
+	// that an entry may be applied more than once.
+	for _, uri := range e.URIs {
+		if uri = trimS(uri); uri != "" && !hasValue(reg.Supplement().URI(), uri) {
+			reg.Supplement().SetURI(uri)
+		}
+	}
+
+	for _, info := range e.Info {
+		if info = trimS(info); info != "" && !hasValue(reg.Supplement().Info(), info) {
+			reg.Supplement().SetInfo(info)
+		}
+	}
+
+	for i := 0; i < len(e.Registrants) && err == nil; i++ {
+		err = r.AttachRegistrant(reg, e.Registrants[i])
+	}
+
+	return
+}
+
+/*
+hasValue returns a Boolean value indicative of the input value being present
+within the input slice.
+*/
+func hasValue(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,214 @@
+package common
+
+/*
+index.go implements the reverse index of names (X.680 identifiers and X.660
+unicode values) to the registrations which bear them.
+*/
+
+import (
+	"sort"
+
+	"github.com/oid-directory/go-radir"
+)
+
+/*
+MatchMode defines the manner in which names are matched by [DIT.FindByName].
+The zero value, [MatchExact], requires an exact match. The remaining values
+may be combined, e.g.: MatchFold|MatchPrefix.
+*/
+type MatchMode uint8
+
+const (
+	MatchExact  MatchMode = 0 // exact, case-sensitive match
+	MatchFold   MatchMode = 1 // case-insensitive match
+	MatchPrefix MatchMode = 2 // match names beginning with the input value
+)
+
+/*
+nameIndex maps names to the registrations which bear them. As names are not
+unique, each name may map to any number of registrations.
+*/
+type nameIndex struct {
+	names  map[string][]*radir.Registration
+	folded map[string][]string              // lower-case name -> names
+	held   map[*radir.Registration][]string // registration -> indexed names
+	keys   []string                         // sorted names
+	fkeys  []string                         // sorted lower-case names
+
+	// Names added, and whether any were dropped, since
+	// the keys were last sorted.
+	add, fadd []string
+	dropped   bool
+}
+
+func newNameIndex() *nameIndex {
+	return &nameIndex{
+		names:  make(map[string][]*radir.Registration),
+		folded: make(map[string][]string),
+		held:   make(map[*radir.Registration][]string),
+	}
+}
+
+/*
+update indexes the names of each of the input *[radir.Registration] instances,
+replacing those under which each was previously indexed, if any.
+*/
+func (r *nameIndex) update(regs []*radir.Registration) {
+	for _, reg := range regs {
+		for _, name := range r.held[reg] {
+			r.drop(name, reg)
+		}
+		delete(r.held, reg)
+
+		id := reg.X680().Identifier()
+		r.put(id, reg)
+		if uv := reg.X660().UnicodeValue(); uv != id {
+			r.put(uv, reg)
+		}
+	}
+
+	if len(r.add) > 0 || r.dropped {
+		r.keys = mergeKeys(r.keys, r.add, func(name string) bool {
+			return len(r.names[name]) > 0
+		})
+		r.fkeys = mergeKeys(r.fkeys, r.fadd, func(fold string) bool {
+			return len(r.folded[fold]) > 0
+		})
+		r.add, r.fadd, r.dropped = nil, nil, false
+	}
+}
+
+func (r *nameIndex) put(name string, reg *radir.Registration) {
+	if name == "" || IsNumber(name) {
+		return
+	}
+
+	if _, found := r.names[name]; !found {
+		fold := lc(name)
+		if _, found = r.folded[fold]; !found {
+			r.fadd = append(r.fadd, fold)
+		}
+		r.folded[fold] = append(r.folded[fold], name)
+		r.add = append(r.add, name)
+	}
+	r.names[name] = append(r.names[name], reg)
+	r.held[reg] = append(r.held[reg], name)
+}
+
+/*
+drop removes the input *[radir.Registration] from the input name, removing
+the name altogether once no registration bears it.
+*/
+func (r *nameIndex) drop(name string, reg *radir.Registration) {
+	regs := r.names[name]
+	for i := range regs {
+		if regs[i] == reg {
+			regs = append(regs[:i:i], regs[i+1:]...)
+			break
+		}
+	}
+
+	if len(regs) > 0 {
+		r.names[name] = regs
+		return
+	}
+	delete(r.names, name)
+	r.dropped = true
+
+	fold := lc(name)
+	names := r.folded[fold]
+	for i := range names {
+		if names[i] == name {
+			names = append(names[:i:i], names[i+1:]...)
+			break
+		}
+	}
+
+	if len(names) > 0 {
+		r.folded[fold] = names
+	} else {
+		delete(r.folded, fold)
+	}
+}
+
+/*
+FindByName returns all *[radir.Registration] instances whose X.680 identifier
+or X.660 unicode value matches the input name according to the input [MatchMode].
+Instances are returned in the order of their names and, for a given name, in
+the order in which they were indexed. No instance appears more than
+once.
+*/
+func (r *DIT) FindByName(name string, mode MatchMode) (regs []*radir.Registration) {
+	if r.IsZero() || r.index == nil || name == "" {
+		return
+	}
+
+	idx := r.index
+	var names []string
+	switch {
+	case mode&MatchPrefix != 0 && mode&MatchFold != 0:
+		for _, key := range prefixed(idx.fkeys, lc(name)) {
+			names = append(names, idx.folded[key]...)
+		}
+		sort.Strings(names)
+	case mode&MatchPrefix != 0:
+		names = prefixed(idx.keys, name)
+	case mode&MatchFold != 0:
+		names = append(names, idx.folded[lc(name)]...)
+		sort.Strings(names)
+	default:
+		names = []string{name}
+	}
+
+	seen := make(map[*radir.Registration]bool)
+	for _, n := range names {
+		for _, reg := range idx.names[n] {
+			if !seen[reg] {
+				seen[reg] = true
+				regs = append(regs, reg)
+			}
+		}
+	}
+
+	return
+}
+
+/*
+prefixed returns the values within the sorted input keys which begin with
+the input prefix.
+*/
+func prefixed(keys []string, pfx string) (match []string) {
+	for i := sort.SearchStrings(keys, pfx); i < len(keys) && hasPfx(keys[i], pfx); i++ {
+		match = append(match, keys[i])
+	}
+
+	return
+}
+
+/*
+mergeKeys returns the sorted input keys merged with the input additions,
+less any value for which the input closure returns false. Duplicates are
+discarded. The cost is linear in the number of keys, rather than that of
+sorting them anew.
+*/
+func mergeKeys(keys, add []string, keep func(string) bool) (merged []string) {
+	sort.Strings(add)
+	merged = make([]string, 0, len(keys)+len(add))
+
+	for i, j := 0, 0; i < len(keys) || j < len(add); {
+		var next string
+		if j == len(add) || i < len(keys) && keys[i] < add[j] {
+			next = keys[i]
+			i++
+		} else {
+			next = add[j]
+			j++
+		}
+
+		if keep(next) && (len(merged) == 0 || merged[len(merged)-1] != next) {
+			merged = append(merged, next)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,136 @@
+package common
+
+/*
+json.go handles the loading of registrations from JSON sources, such as
+those produced by the WriteJSON and WriteJSONLines methods of this package.
+*/
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+/*
+LoadJSONRegistry returns an error following an attempt to load the [Entry]
+instances supplied by the input [io.Reader] instance, which may contain
+either a single JSON array of (possibly nested) entries, or any number of
+consecutive JSON objects, such as JSON Lines, one entry per object.
+
+Entries are applied in the order in which they appear, each entry being
+applied before its children. See [DIT.ApplyEntry] for details.
+*/
+func LoadJSONRegistry(r *DIT, src io.Reader) (err error) {
+	if r.IsZero() || src == nil {
+		return mkerr("DIT or JSON source is nil")
+	}
+
+	var apply Applier
+	if apply, err = ParseJSONRegistry(src, r.Options()); err == nil {
+		err = apply(r)
+	}
+
+	return
+}
+
+/*
+ParseJSONRegistry returns an [Applier] alongside an error following an attempt
+to decode the JSON content supplied by the input [io.Reader] instance. The
+entries are loaded, as described for [LoadJSONRegistry], only once the return
+[Applier] is called.
+*/
+func ParseJSONRegistry(src io.Reader, _ ImportOptions) (apply Applier, err error) {
+	if src == nil {
+		err = mkerr("JSON source is nil")
+		return
+	}
+
+	var entries []*Entry
+	if entries, err = decodeEntries(src); err != nil {
+		return
+	}
+
+	apply = func(r *DIT) (err error) {
+		if r.IsZero() {
+			return mkerr("DIT is nil")
+		}
+
+		var count int
+		for i := 0; i < len(entries) && err == nil; i++ {
+			err = r.applyEntries(entries[i], &count)
+		}
+
+		return
+	}
+
+	return
+}
+
+/*
+decodeEntries returns the [Entry] instances decoded from the input [io.Reader],
+which may bear either a JSON array or a series of JSON objects.
+*/
+func decodeEntries(src io.Reader) (entries []*Entry, err error) {
+	br := bufio.NewReader(src)
+
+	var c byte
+	for {
+		if c, err = br.ReadByte(); err != nil {
+			if err == eof {
+				err = mkerr("JSON content is empty")
+			}
+			return
+		} else if !(c == ' ' || c == '\t' || c == '\r' || c == '\n') {
+			break
+		}
+	}
+	_ = br.UnreadByte()
+
+	dec := json.NewDecoder(br)
+	dec.DisallowUnknownFields()
+
+	if c == '[' {
+		if err = dec.Decode(&entries); err != nil {
+			err = mkerr("JSON array: " + err.Error())
+		}
+		return
+	}
+
+	for n := 1; ; n++ {
+		e := new(Entry)
+		if err = dec.Decode(e); err != nil {
+			if err == eof {
+				err = nil
+			} else {
+				err = mkerr("JSON object " + itoa(n) + ": " + err.Error())
+			}
+			break
+		}
+		entries = append(entries, e)
+	}
+
+	return
+}
+
+/*
+applyEntries applies the input [Entry] and all of its children, in that
+order. The input count tallies the entries applied thus far, and is used
+to identify any entry which could not be applied.
+*/
+func (r *DIT) applyEntries(e *Entry, count *int) (err error) {
+	if e == nil {
+		return
+	}
+
+	*count++
+	if _, err = r.ApplyEntry(e); err != nil {
+		err = mkerr("JSON entry " + itoa(*count) + ": " + err.Error())
+		return
+	}
+
+	for i := 0; i < len(e.Children) && err == nil; i++ {
+		err = r.applyEntries(e.Children[i], count)
+	}
+
+	return
+}
@@ -0,0 +1,440 @@
+package common
+
+/*
+ldif.go handles the parsing of LDIF content records (RFC 2849), such as
+those produced by the Write methods of this package, and the loading of
+such records into a *DIT.
+*/
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+
+	"github.com/oid-directory/go-radir"
+	"github.com/oid-directory/go-radir/oid"
+)
+
+/*
+LDIFEntry contains a single LDIF content record.
+*/
+type LDIFEntry struct {
+	DN    string              // distinguished name
+	Attrs map[string][]string // attribute values, keyed by attribute type
+	Types []string            // attribute types in order of first appearance
+	Line  int                 // line number of the dn line
+}
+
+/*
+Values returns the values of the input attribute type, which is matched
+without regard to case.
+*/
+func (r LDIFEntry) Values(typ string) (vals []string) {
+	for _, t := range r.Types {
+		if eq(t, typ) {
+			vals = append(vals, r.Attrs[t]...)
+		}
+	}
+
+	return
+}
+
+/*
+Value returns the first value of the input attribute type, or a zero
+string if no such value exists.
+*/
+func (r LDIFEntry) Value(typ string) (val string) {
+	if vals := r.Values(typ); len(vals) > 0 {
+		val = vals[0]
+	}
+
+	return
+}
+
+/*
+HasObjectClass returns a Boolean value indicative of the receiver bearing
+the input objectClass, which is matched without regard to case.
+*/
+func (r LDIFEntry) HasObjectClass(oc string) bool {
+	for _, val := range r.Values(`objectClass`) {
+		if eq(val, oc) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *LDIFEntry) add(typ, val string) {
+	if _, found := r.Attrs[typ]; !found {
+		r.Types = append(r.Types, typ)
+	}
+	r.Attrs[typ] = append(r.Attrs[typ], val)
+}
+
+/*
+ParseLDIF returns a slice of [LDIFEntry] instances alongside an error following
+an attempt to parse the LDIF content records supplied by the input [io.Reader].
+
+Folded lines, comments, the optional version line and base64-encoded values
+are supported. Change records and URL-referenced values are not supported.
+*/
+func ParseLDIF(src io.Reader) (entries []LDIFEntry, err error) {
+	if src == nil {
+		err = mkerr("LDIF source is nil")
+		return
+	}
+
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var (
+		lines   []string // unfolded lines of the current record
+		numbers []int    // line number of each unfolded line
+		num     int
+	)
+
+	flush := func() (err error) {
+		if len(lines) > 0 {
+			var entry LDIFEntry
+			if entry, err = parseLDIFRecord(lines, numbers); err == nil && entry.DN != "" {
+				entries = append(entries, entry)
+			}
+			lines, numbers = nil, nil
+		}
+		return
+	}
+
+	var comment bool // whether the last line was a comment
+	for scanner.Scan() && err == nil {
+		num++
+		line := trimR(scanner.Text(), "\r")
+		switch {
+		case line == "":
+			comment = false
+			err = flush()
+		case line[0] == ' ':
+			// continuation of the previous line
+			if comment {
+				continue
+			} else if len(lines) == 0 {
+				err = mkerr("LDIF line " + itoa(num) + ": continuation without preceding line")
+			} else {
+				lines[len(lines)-1] += line[1:]
+			}
+		case line[0] == '#':
+			comment = true
+		default:
+			comment = false
+			lines = append(lines, line)
+			numbers = append(numbers, num)
+		}
+	}
+
+	if err == nil {
+		if err = scanner.Err(); err == nil {
+			err = flush()
+		}
+	}
+
+	return
+}
+
+func parseLDIFRecord(lines []string, numbers []int) (entry LDIFEntry, err error) {
+	entry.Attrs = make(map[string][]string)
+
+	for i, line := range lines {
+		var typ, val string
+		if typ, val, err = parseLDIFLine(line); err != nil {
+			err = mkerr("LDIF line " + itoa(numbers[i]) + ": " + err.Error())
+			return
+		}
+
+		switch {
+		case eq(typ, `version`) && i == 0:
+			// ignore the optional version line
+		case eq(typ, `dn`):
+			if entry.DN != "" {
+				err = mkerr("LDIF line " + itoa(numbers[i]) + ": multiple dn lines")
+				return
+			}
+			entry.DN, entry.Line = val, numbers[i]
+		case eq(typ, `changetype`):
+			err = mkerr("LDIF line " + itoa(numbers[i]) + ": change records are not supported")
+			return
+		case entry.DN == "":
+			err = mkerr("LDIF line " + itoa(numbers[i]) + ": attribute precedes dn")
+			return
+		default:
+			entry.add(typ, val)
+		}
+	}
+
+	return
+}
+
+/*
+parseLDIFLine returns the attribute type and value of the input unfolded
+line, decoding base64 values as needed.
+*/
+func parseLDIFLine(line string) (typ, val string, err error) {
+	idx := idxr(line, ':')
+	if idx < 1 {
+		err = mkerr("missing attribute type or separator")
+		return
+	}
+
+	typ, val = line[:idx], line[idx+1:]
+	switch {
+	case hasPfx(val, `:`):
+		var b []byte
+		if b, err = base64.StdEncoding.DecodeString(trimS(val[1:])); err != nil {
+			err = mkerr("invalid base64 value for " + typ)
+		}
+		val = string(b)
+	case hasPfx(val, `<`):
+		err = mkerr("URL values are not supported")
+	default:
+		val = trimL(val, ` `)
+	}
+
+	return
+}
+
+/*
+ldifRegistry contains the entries of an LDIF source, grouped for loading.
+*/
+type ldifRegistry struct {
+	entries []LDIFEntry
+}
+
+/*
+LoadLDIFRegistry returns an error following an attempt to load the LDIF content
+records supplied by the input [io.Reader] instance, such as those produced by
+the Write methods of this package.
+
+Entries bearing a dotNotation attribute (or, failing that, a DN composed of
+number form RDNs) are registrations, and are allocated beneath the appropriate
+root. Entries bearing a registrant objectClass are registrants. All other
+entries are subentries, whose attributes are merged into the registration
+whose DN is immediately superior.
+*/
+func LoadLDIFRegistry(r *DIT, src io.Reader) (err error) {
+	if r.IsZero() || src == nil {
+		return mkerr("DIT or LDIF source is nil")
+	}
+
+	var apply Applier
+	if apply, err = ParseLDIFRegistry(src, r.Options()); err == nil {
+		err = apply(r)
+	}
+
+	return
+}
+
+/*
+ParseLDIFRegistry returns an [Applier] alongside an error following an attempt
+to parse the LDIF content records supplied by the input [io.Reader] instance.
+The records are loaded, as described for [LoadLDIFRegistry], only once the
+return [Applier] is called.
+*/
+func ParseLDIFRegistry(src io.Reader, _ ImportOptions) (apply Applier, err error) {
+	var ldr ldifRegistry
+	if ldr.entries, err = ParseLDIF(src); err == nil {
+		apply = ldr.apply
+	}
+
+	return
+}
+
+func (r ldifRegistry) apply(dit *DIT) (err error) {
+	if dit.IsZero() {
+		return mkerr("DIT is nil")
+	}
+
+	var (
+		regs  []*radir.Registration
+		attrs []map[string][]string
+		byDN  = make(map[string]int)
+	)
+
+	for _, entry := range r.entries {
+		switch {
+		case entry.HasObjectClass(`registrant`):
+			err = dit.loadLDIFRegistrant(entry)
+		case entry.Value(`dotNotation`) != "" || dotFromDN(entry.DN) != "":
+			var reg *radir.Registration
+			if reg, err = dit.allocateLDIF(entry); err == nil {
+				byDN[lc(entry.DN)] = len(regs)
+				regs = append(regs, reg)
+				attrs = append(attrs, copyAttrs(entry))
+			}
+		default:
+			// subentry: merge into the immediately superior registration.
+			idx, found := byDN[lc(parentDN(entry.DN))]
+			if !found {
+				err = mkerr("no registration found for subentry " + entry.DN)
+				break
+			}
+			for _, typ := range entry.Types {
+				if !eq(typ, `cn`) && !eq(typ, `objectClass`) {
+					attrs[idx][typ] = append(attrs[idx][typ], entry.Attrs[typ]...)
+				}
+			}
+		}
+
+		if err != nil {
+			err = mkerr("LDIF line " + itoa(entry.Line) + ": " + err.Error())
+			return
+		}
+	}
+
+	for i, reg := range regs {
+		if err = reg.Marshal(attrs[i]); err != nil {
+			err = mkerr("unable to marshal " + reg.DN() + ": " + err.Error())
+			break
+		}
+	}
+
+	return
+}
+
+/*
+allocateLDIF returns the *[radir.Registration] allocated for the input entry.
+*/
+func (r *DIT) allocateLDIF(entry LDIFEntry) (reg *radir.Registration, err error) {
+	dot := entry.Value(`dotNotation`)
+	if dot == "" {
+		dot = dotFromDN(entry.DN)
+	}
+
+	if !(IsNumber(dot) || oid.IsDotNotation(dot)) {
+		err = mkerr("Invalid dotNotation '" + dot + "'")
+		return
+	}
+
+	n, _ := atoi(split(dot, `.`)[0])
+	if reg = r.Root(n); reg.IsZero() {
+		err = mkerr("Unsupported root for " + dot)
+	} else if ctns(dot, `.`) {
+		if reg = reg.Allocate(dot); reg.IsZero() {
+			err = mkerr("Allocation error: " + dot)
+		}
+	}
+
+	if err == nil {
+		reg.SetDN(entry.DN)
+		r.Touch(reg)
+	}
+
+	return
+}
+
+func (r *DIT) loadLDIFRegistrant(entry LDIFEntry) (err error) {
+	if !r.profile.Dedicated() {
+		return mkerr("Registrant entries require the Dedicated Registrants Policy")
+	}
+
+	athy := r.aths.Get(entry.DN)
+	fresh := athy.IsZero()
+	if fresh {
+		athy = r.profile.NewRegistrant()
+	}
+
+	// The registrant is pushed, and indexed, once it bears its DN.
+	if err = athy.Marshal(copyAttrs(entry)); err == nil {
+		athy.SetDN(entry.DN)
+		if fresh {
+			r.aths.Push(athy)
+		}
+		r.indexRegistrants()
+	}
+
+	return
+}
+
+func copyAttrs(entry LDIFEntry) (attrs map[string][]string) {
+	attrs = make(map[string][]string, len(entry.Attrs))
+	for typ, vals := range entry.Attrs {
+		attrs[typ] = append([]string{}, vals...)
+	}
+
+	return
+}
+
+/*
+SplitDN returns the comma-delimited RDNs of the input DN, verbatim. Commas
+escaped per RFC 4514, e.g.: "cn=Smith\, John", do not delimit RDNs.
+*/
+func SplitDN(dn string) (rdns []string) {
+	var start int
+	for i := 0; i < len(dn); i++ {
+		if dn[i] == '\\' {
+			i++
+		} else if dn[i] == ',' {
+			rdns = append(rdns, dn[start:i])
+			start = i + 1
+		}
+	}
+	rdns = append(rdns, dn[start:])
+
+	return
+}
+
+/*
+parentDN returns the input DN without its leading RDN.
+*/
+func parentDN(dn string) (parent string) {
+	if rdns := SplitDN(dn); len(rdns) > 1 {
+		parent = trimS(dn[len(rdns[0])+1:])
+	}
+
+	return
+}
+
+/*
+dotFromDN returns the dotNotation implied by the leading sequence of number
+form ("n=") RDNs within the input DN, as used within the two dimensional
+model, or a zero string if the DN does not begin with such an RDN.
+*/
+func dotFromDN(dn string) (dot string) {
+	var arcs []string
+	for _, rdn := range SplitDN(dn) {
+		val, found := cutPfx(lc(trimS(rdn)), `n=`)
+		if !found || !IsNumber(val) {
+			break
+		}
+		arcs = append([]string{val}, arcs...)
+	}
+
+	return join(arcs, `.`)
+}
+
+/*
+LDIFLine returns the input attribute type and value as a single LDIF line,
+terminated by a newline. Values which are not SAFE-STRING values per RFC 2849
+are base64-encoded.
+*/
+func LDIFLine(typ, val string) string {
+	if !safeLDIFString(val) {
+		return typ + `:: ` + base64.StdEncoding.EncodeToString([]byte(val)) + "\n"
+	}
+
+	return typ + `: ` + val + "\n"
+}
+
+func safeLDIFString(val string) bool {
+	if val == "" {
+		return true
+	} else if c := val[0]; c == ' ' || c == ':' || c == '<' || val[len(val)-1] == ' ' {
+		return false
+	}
+
+	for i := 0; i < len(val); i++ {
+		if c := val[i]; c == 0 || c == '\n' || c == '\r' || c > 127 {
+			return false
+		}
+	}
+
+	return true
+}
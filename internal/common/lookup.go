@@ -0,0 +1,231 @@
+package common
+
+/*
+lookup.go handles the resolution of registrations by way of dotNotation,
+ASN.1 notation, IRI and identifier path values.
+*/
+
+import (
+	"github.com/oid-directory/go-radir"
+	"github.com/oid-directory/go-radir/oid"
+)
+
+/*
+ErrNotFound is matched, by way of [errors.Is], by all instances of
+[NotFoundError].
+*/
+var ErrNotFound error = mkerr("Registration not found")
+
+/*
+NotFoundError is returned when a syntactically valid value does not
+identify any registration present within a *[DIT] instance.
+*/
+type NotFoundError struct {
+	Value string // the value which was not found
+}
+
+/*
+Error returns the string representation of the receiver instance.
+*/
+func (r NotFoundError) Error() string {
+	return ErrNotFound.Error() + ": " + r.Value
+}
+
+/*
+Is returns a Boolean value indicative of the input error being [ErrNotFound].
+*/
+func (r NotFoundError) Is(err error) bool {
+	return err == ErrNotFound
+}
+
+/*
+lookupArc describes a single arc of an OID by way of a name, a number form
+or both. The number form, when present, takes precedence.
+*/
+type lookupArc struct {
+	name string
+	n    string
+}
+
+/*
+Lookup returns the *[radir.Registration] identified by the input dotNotation
+value alongside an error, which shall be an instance of [NotFoundError] if no
+such registration exists.
+*/
+func (r *DIT) Lookup(dot string) (reg *radir.Registration, err error) {
+	if dot = trimS(dot); !(IsNumber(dot) || oid.IsDotNotation(dot)) {
+		err = mkerr("Invalid dotNotation '" + dot + "'")
+		return
+	}
+
+	if reg = r.Resolve(dot); reg.IsZero() {
+		err = NotFoundError{Value: dot}
+	}
+
+	return
+}
+
+/*
+LookupASN1 returns the *[radir.Registration] identified by the input ASN.1
+notation alongside an error, which shall be an instance of [NotFoundError]
+if no such registration exists.
+
+Each arc may be expressed in NameAndNumber form, e.g.: "dod(6)", number form,
+e.g.: "6", or name form, e.g.: "dod". Arcs bearing a number form are matched
+numerically regardless of any accompanying name, while those bearing a name
+alone are matched against the X.680 identifiers of the appropriate children.
+
+	{iso(1) identified-organization(3) dod(6) internet(1) private(4)}
+*/
+func (r *DIT) LookupASN1(asn string) (reg *radir.Registration, err error) {
+	a := trimS(asn)
+	if !hasPfx(a, `{`) || !hasSfx(a, `}`) {
+		err = mkerr("Invalid ASN.1 notation '" + asn + "': braces required")
+		return
+	}
+
+	var arcs []lookupArc
+	for _, field := range fields(a[1 : len(a)-1]) {
+		var arc lookupArc
+		if idx := idxr(field, '('); idx != -1 && hasSfx(field, `)`) {
+			arc.name, arc.n = field[:idx], field[idx+1:len(field)-1]
+		} else if IsNumber(field) {
+			arc.n = field
+		} else {
+			arc.name = field
+		}
+
+		if arc.n != "" && !IsNumber(arc.n) ||
+			arc.name != "" && !oid.IsNameForm(arc.name) {
+			err = mkerr("Invalid ASN.1 notation '" + asn + "': bad arc '" + field + "'")
+			return
+		}
+		arcs = append(arcs, arc)
+	}
+
+	return r.lookupArcs(asn, arcs, func(reg *radir.Registration) string {
+		return reg.X680().Identifier()
+	})
+}
+
+/*
+LookupIRI returns the *[radir.Registration] identified by the input OID-IRI
+alongside an error, which shall be an instance of [NotFoundError] if no such
+registration exists.
+
+Each arc is matched either numerically or against the X.660 unicode value
+of the appropriate child, e.g.:
+
+	/ISO/Identified-Organization/6/1/4/1
+*/
+func (r *DIT) LookupIRI(iri string) (reg *radir.Registration, err error) {
+	i := trimS(iri)
+	if !hasPfx(i, `/`) || len(i) == 1 {
+		err = mkerr("Invalid OID-IRI '" + iri + "'")
+		return
+	}
+
+	var arcs []lookupArc
+	for _, label := range split(i[1:], `/`) {
+		if label == "" {
+			err = mkerr("Invalid OID-IRI '" + iri + "': empty arc")
+			return
+		} else if IsNumber(label) {
+			arcs = append(arcs, lookupArc{n: label})
+		} else {
+			arcs = append(arcs, lookupArc{name: label})
+		}
+	}
+
+	return r.lookupArcs(iri, arcs, func(reg *radir.Registration) string {
+		return reg.X660().UnicodeValue()
+	})
+}
+
+/*
+LookupIdentifiers returns the *[radir.Registration] identified by the input
+sequence of X.680 identifiers alongside an error, which shall be an instance
+of [NotFoundError] if no such registration exists. Number forms may be used
+in place of any identifier, e.g.:
+
+	r.LookupIdentifiers("iso", "identified-organization", "dod", "internet")
+*/
+func (r *DIT) LookupIdentifiers(ids ...string) (reg *radir.Registration, err error) {
+	if len(ids) == 0 {
+		err = mkerr("No identifiers specified")
+		return
+	}
+
+	var arcs []lookupArc
+	for _, id := range ids {
+		if IsNumber(id) {
+			arcs = append(arcs, lookupArc{n: id})
+		} else if oid.IsNameForm(id) {
+			arcs = append(arcs, lookupArc{name: id})
+		} else {
+			err = mkerr("Invalid identifier '" + id + "'")
+			return
+		}
+	}
+
+	return r.lookupArcs(join(ids, `.`), arcs, func(reg *radir.Registration) string {
+		return reg.X680().Identifier()
+	})
+}
+
+/*
+lookupArcs returns the *[radir.Registration] identified by the input arcs.
+If all arcs bear a number form, the registration is resolved by way of its
+dotNotation. Otherwise, the children at each level are searched, wherein the
+input name closure returns the name against which a name-only arc is matched.
+*/
+func (r *DIT) lookupArcs(val string, arcs []lookupArc, name func(*radir.Registration) string) (reg *radir.Registration, err error) {
+	if r.IsZero() {
+		err = mkerr("DIT is nil")
+		return
+	}
+
+	dot := make([]string, len(arcs))
+	for i := 0; i < len(arcs) && dot != nil; i++ {
+		if dot[i] = arcs[i].n; dot[i] == "" {
+			dot = nil
+		}
+	}
+
+	if dot != nil {
+		reg = r.Resolve(join(dot, `.`))
+	} else {
+		cands := []*radir.Registration{r.tree[0], r.tree[1], r.tree[2]}
+		for i := 0; i < len(arcs); i++ {
+			if reg = matchArc(cands, arcs[i], name); reg.IsZero() {
+				break
+			}
+
+			kids := reg.Children()
+			cands = make([]*radir.Registration, kids.Len())
+			for j := 0; j < kids.Len(); j++ {
+				cands[j] = kids.Index(j)
+			}
+		}
+	}
+
+	if reg.IsZero() {
+		err = NotFoundError{Value: val}
+	}
+
+	return
+}
+
+func matchArc(cands []*radir.Registration, arc lookupArc, name func(*radir.Registration) string) (reg *radir.Registration) {
+	for _, cand := range cands {
+		if cand.IsZero() {
+			continue
+		} else if arc.n != "" && cand.X680().N() == arc.n ||
+			arc.n == "" && name(cand) == arc.name {
+			reg = cand
+			break
+		}
+	}
+
+	return
+}
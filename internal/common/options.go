@@ -0,0 +1,37 @@
+package common
+
+/*
+ImportOptions contains the settings which govern the behavior of the
+various importers. The zero value reflects the default behavior.
+*/
+type ImportOptions struct {
+	// KeepObsolete imports SMI records which are perceived to be
+	// obsolete, rather than skipping them. Such registrations bear
+	// a status of OBSOLETE and, where a <footnote> is referenced,
+	// its text as information.
+	KeepObsolete bool
+
+	// StrictPEN aborts a PEN Registry import upon the first irregular
+	// entry, such as a decimal which does not exceed its predecessor or
+	// a missing organization, contact or email line. By default, such
+	// entries are reported and either tolerated or skipped.
+	StrictPEN bool
+
+	// Concurrent parses all sources of a single import in parallel
+	// goroutines, after which the parsed content is applied to the
+	// DIT one source at a time, in the usual order. The resulting
+	// tree is identical to that of a sequential import, at the cost
+	// of holding each parsed source in memory until it is applied.
+	// In particular, the SMI and LDAP registries are not streamed as
+	// they are during a sequential import, but are decoded in full
+	// beforehand. Memory-constrained callers should leave this unset.
+	Concurrent bool
+}
+
+/*
+Applier implements a closure which applies previously parsed content to a
+*[DIT] instance. Parsing functions return instances of this type so that
+multiple sources may be parsed concurrently and then applied in a fixed
+order.
+*/
+type Applier func(*DIT) error
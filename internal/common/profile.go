@@ -0,0 +1,295 @@
+package common
+
+/*
+profile.go implements the loading and validation of DIT profile
+configurations, which describe the layout of the DIT independently
+of any code.
+*/
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/oid-directory/go-radir"
+)
+
+/*
+DIT models, as used within [ProfileConfig.Model].
+*/
+const (
+	Model2D = `2D` // registrations beneath a single base (two dimensional)
+	Model3D = `3D` // registrations nested according to their arcs (three dimensional)
+)
+
+/*
+Registrants policies, as used within [ProfileConfig.RegistrantsPolicy].
+*/
+const (
+	PolicyDedicated = `dedicated` // registrants are distinct entries
+	PolicyCombined  = `combined`  // registrant details reside within registrations
+)
+
+/*
+ProfileConfig describes the layout of a DIT. Zero values retain those of the
+factory default DUA configuration.
+
+Instances of this type are typically read from a JSON file, e.g.:
+
+	{
+	  "registrationBase": "ou=Registrations,o=rA",
+	  "registrantBase": "ou=Registrants,o=rA",
+	  "model": "3D",
+	  "registrantsPolicy": "dedicated"
+	}
+
+... or from an equivalent YAML file consisting of one "key: value" pair
+per line.
+*/
+type ProfileConfig struct {
+	// RegistrationBase contains the DN beneath which registrations
+	// reside.
+	RegistrationBase string `json:"registrationBase,omitempty"`
+
+	// RegistrantBase contains the DN beneath which registrants reside.
+	// This is only meaningful under the "dedicated" registrants policy.
+	RegistrantBase string `json:"registrantBase,omitempty"`
+
+	// Model is either "2D" or "3D", matched without regard to case.
+	Model string `json:"model,omitempty"`
+
+	// RegistrantsPolicy is either "dedicated" or "combined", matched
+	// without regard to case.
+	RegistrantsPolicy string `json:"registrantsPolicy,omitempty"`
+}
+
+/*
+Validate returns an error describing the first invalid setting found within
+the receiver instance, if any.
+*/
+func (r ProfileConfig) Validate() (err error) {
+	for _, dn := range []struct{ name, val string }{
+		{`registrationBase`, r.RegistrationBase},
+		{`registrantBase`, r.RegistrantBase},
+	} {
+		if dn.val != "" && !isDN(dn.val) {
+			return mkerr("Invalid " + dn.name + " DN '" + dn.val + "'")
+		}
+	}
+
+	switch {
+	case r.RegistrationBase != "" && eq(r.RegistrationBase, r.RegistrantBase):
+		err = mkerr("registrationBase and registrantBase must differ")
+	case !(r.Model == "" || eq(r.Model, Model2D) || eq(r.Model, Model3D)):
+		err = mkerr("Unknown model '" + r.Model + "'; expected " + Model2D + " or " + Model3D)
+	case !(r.RegistrantsPolicy == "" || eq(r.RegistrantsPolicy, PolicyDedicated) ||
+		eq(r.RegistrantsPolicy, PolicyCombined)):
+		err = mkerr("Unknown registrantsPolicy '" + r.RegistrantsPolicy +
+			"'; expected " + PolicyDedicated + " or " + PolicyCombined)
+	case r.RegistrantBase != "" && eq(r.RegistrantsPolicy, PolicyCombined):
+		err = mkerr("registrantBase requires the " + PolicyDedicated + " registrantsPolicy")
+	}
+
+	return
+}
+
+/*
+Profile returns a new *[radir.DITProfile] instance, based upon that of the
+factory default DUA configuration, alongside an error following validation
+of the receiver instance.
+*/
+func (r ProfileConfig) Profile() (profile *radir.DITProfile, err error) {
+	if err = r.Validate(); err != nil {
+		return
+	}
+
+	if profile = radir.NewFactoryDefaultDUAConfig().Profile(); profile.IsZero() {
+		err = mkerr("Factory default DIT profile is nil")
+		return
+	}
+
+	if r.RegistrationBase != "" {
+		profile.SetRegistrationBase(r.RegistrationBase)
+	}
+	if r.RegistrantBase != "" {
+		profile.SetRegistrantBase(r.RegistrantBase)
+	}
+
+	if eq(r.Model, Model2D) {
+		profile.SetModel(radir.TwoDimensional)
+	} else if eq(r.Model, Model3D) {
+		profile.SetModel(radir.ThreeDimensional)
+	}
+
+	if eq(r.RegistrantsPolicy, PolicyDedicated) {
+		profile.SetCombined(false)
+		profile.SetDedicated(true)
+	} else if eq(r.RegistrantsPolicy, PolicyCombined) {
+		profile.SetDedicated(false)
+		profile.SetCombined(true)
+	}
+
+	return
+}
+
+/*
+ParseProfileConfig returns an instance of [ProfileConfig] alongside an error
+following an attempt to read the input [io.Reader]. Content beginning with '{'
+is read as JSON, while all other content is read as a flat YAML mapping of one
+"key: value" pair per line, optionally quoted, with '#' comments. Nested YAML
+structures are not supported. Unknown keys are rejected in either case.
+
+The return instance is not validated.
+*/
+func ParseProfileConfig(src io.Reader) (cfg ProfileConfig, err error) {
+	if src == nil {
+		err = mkerr("Profile configuration source is nil")
+		return
+	}
+
+	br := bufio.NewReader(src)
+	var c byte
+	for {
+		if c, err = br.ReadByte(); err != nil {
+			if err == eof {
+				err = mkerr("Profile configuration is empty")
+			}
+			return
+		} else if !(c == ' ' || c == '\t' || c == '\r' || c == '\n') {
+			break
+		}
+	}
+	_ = br.UnreadByte()
+
+	if c == '{' {
+		dec := json.NewDecoder(br)
+		dec.DisallowUnknownFields()
+		if err = dec.Decode(&cfg); err != nil {
+			err = mkerr("Profile configuration JSON: " + err.Error())
+		}
+		return
+	}
+
+	err = parseProfileYAML(br, &cfg)
+
+	return
+}
+
+func parseProfileYAML(src io.Reader, cfg *ProfileConfig) (err error) {
+	settings := map[string]*string{
+		`registrationbase`:  &cfg.RegistrationBase,
+		`registrantbase`:    &cfg.RegistrantBase,
+		`model`:             &cfg.Model,
+		`registrantspolicy`: &cfg.RegistrantsPolicy,
+	}
+
+	scanner := newScan(src)
+	for n := 1; scanner.Scan(); n++ {
+		line := trimR(scanner.Text(), " \t\r")
+		if trimS(line) == "" || hasPfx(trimS(line), `#`) || line == `---` {
+			continue
+		} else if line[0] == ' ' || line[0] == '\t' {
+			return mkerr("Profile configuration YAML line " + itoa(n) + ": nested values are not supported")
+		}
+
+		key, val, found := strings.Cut(line, `:`)
+		if !found {
+			return mkerr("Profile configuration YAML line " + itoa(n) + ": expected 'key: value'")
+		}
+
+		ptr, known := settings[lc(trimS(key))]
+		if !known {
+			return mkerr("Profile configuration YAML line " + itoa(n) + ": unknown key '" + trimS(key) + "'")
+		}
+
+		if *ptr, err = yamlScalar(trimS(val)); err != nil {
+			return mkerr("Profile configuration YAML line " + itoa(n) + ": " + err.Error())
+		}
+	}
+
+	return scanner.Err()
+}
+
+/*
+yamlScalar returns the input plain, single-quoted or double-quoted YAML scalar,
+stripped of any trailing comment and unquoted.
+*/
+func yamlScalar(val string) (out string, err error) {
+	switch {
+	case hasPfx(val, `"`):
+		end := 1
+		for ; end < len(val) && val[end] != '"'; end++ {
+			if val[end] == '\\' {
+				end++
+			}
+		}
+		if end >= len(val) || !yamlTail(val[end+1:]) {
+			err = mkerr("malformed double-quoted value")
+		} else {
+			out, err = strconv.Unquote(val[:end+1])
+		}
+	case hasPfx(val, `'`):
+		var b strings.Builder
+		end := 1
+		for ; end < len(val); end++ {
+			if val[end] == '\'' {
+				if end+1 < len(val) && val[end+1] == '\'' {
+					end++
+				} else {
+					break
+				}
+			}
+			b.WriteByte(val[end])
+		}
+		if end >= len(val) || !yamlTail(val[end+1:]) {
+			err = mkerr("malformed single-quoted value")
+		} else {
+			out = b.String()
+		}
+	default:
+		// A comment requires preceding whitespace.
+		if idx := sidx(val, ` #`); idx >= 0 {
+			val = val[:idx]
+		} else if hasPfx(val, `#`) {
+			val = ``
+		}
+		out = trimS(val)
+	}
+
+	return
+}
+
+/*
+yamlTail returns a Boolean value indicative of the input value, which follows
+a quoted scalar, being empty or a comment.
+*/
+func yamlTail(tail string) bool {
+	tail = trimS(tail)
+	return tail == "" || hasPfx(tail, `#`)
+}
+
+/*
+isDN returns a Boolean value indicative of the input value resembling a
+distinguished name: one or more comma-delimited "type=value" pairs, each
+type being a descr or numericoid. Escaped commas within values are honored.
+*/
+func isDN(dn string) bool {
+	for _, rdn := range SplitDN(dn) {
+		typ, val, found := strings.Cut(trimS(rdn), `=`)
+		if !found || val == "" || typ == "" {
+			return false
+		}
+
+		for i, c := range typ {
+			alnum := 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' ||
+				'0' <= c && c <= '9'
+			if !(alnum || i > 0 && (c == '-' || c == '.')) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,212 @@
+package common
+
+/*
+report.go implements the import report, which describes the records that
+were skipped or altered, and the irregularities that were tolerated, while
+loading the contents of a source.
+*/
+
+/*
+ImportReport describes the outcome of one or more imports. Instances of
+this type are automatically initialized within instances of *[DIT], and
+accumulate across imports.
+*/
+type ImportReport struct {
+	// Skipped lists each record which was not imported.
+	Skipped []SkippedRecord
+
+	// Rewrites lists each identifier which was replaced or augmented
+	// in order to produce a legal X.680 identifier.
+	Rewrites []IdentifierRewrite
+
+	// Warnings lists each irregularity which was tolerated, such as a
+	// missing line within a PEN Registry entry.
+	Warnings []ImportWarning
+
+	source string
+}
+
+/*
+SkippedRecord describes a single record which was not imported.
+*/
+type SkippedRecord struct {
+	Source   string // source key, e.g.: "smifile"
+	Registry string // registry ID, e.g.: "smi-numbers-3"
+	Value    string // record value, e.g.: "42" or "1-10"
+	Reason   string // reason for omission
+}
+
+/*
+IdentifierRewrite describes a single identifier which was derived from
+something other than the verbatim name of a record.
+*/
+type IdentifierRewrite struct {
+	Source     string // source key, e.g.: "smifile"
+	Registry   string // registry ID, e.g.: "smi-numbers-3"
+	Value      string // record value, e.g.: "42"
+	Original   string // name as it appeared within the source, if any
+	Identifier string // resulting identifier, or the number form if none
+
+	// Methods lists each of the Rewrite* constants applied, in the
+	// order in which they were applied, e.g.: the description was used
+	// in lieu of a missing name, and was then legalized.
+	Methods []string
+}
+
+/*
+ImportWarning describes a single irregularity which was tolerated.
+*/
+type ImportWarning struct {
+	Source  string // source key, e.g.: "penfile"
+	Line    int    // source line number, if known
+	Message string
+}
+
+/*
+Identifier rewrite methods, as used within [IdentifierRewrite.Methods].
+*/
+const (
+	RewriteLegalized   = `legalizeIdentifier` // name was altered for legality
+	RewritePatched     = `patchMissingName`   // missing name was supplied from a known table
+	RewriteDescription = `description`        // name was derived from the description
+	RewriteNumberForm  = `numberForm`         // no legal name found; number form used
+)
+
+/*
+SourceCounts contains the number of skipped records and identifier rewrites
+reported for a single source.
+*/
+type SourceCounts struct {
+	Skipped  int
+	Rewrites int
+	Warnings int
+}
+
+/*
+NewImportReport returns a freshly initialized instance of *[ImportReport].
+*/
+func NewImportReport() *ImportReport {
+	return &ImportReport{
+		Skipped:  make([]SkippedRecord, 0),
+		Rewrites: make([]IdentifierRewrite, 0),
+		Warnings: make([]ImportWarning, 0),
+	}
+}
+
+/*
+IsZero returns a Boolean value indicative of a nil receiver state.
+*/
+func (r *ImportReport) IsZero() bool {
+	return r == nil
+}
+
+/*
+Begin sets the source key with which subsequent report entries are tagged.
+*/
+func (r *ImportReport) Begin(source string) {
+	if !r.IsZero() {
+		r.source = source
+	}
+}
+
+/*
+Skip records the omission of the record identified by the input registry ID
+and value for the input reason.
+*/
+func (r *ImportReport) Skip(registry, value, reason string) {
+	if !r.IsZero() {
+		r.Skipped = append(r.Skipped, SkippedRecord{
+			Source:   r.source,
+			Registry: registry,
+			Value:    value,
+			Reason:   reason,
+		})
+	}
+}
+
+/*
+Rewrite records the derivation of identifier from original by way of the
+input methods, in the order applied, for the record identified by the input
+registry ID and value.
+*/
+func (r *ImportReport) Rewrite(registry, value, original, identifier string, methods ...string) {
+	if !r.IsZero() {
+		r.Rewrites = append(r.Rewrites, IdentifierRewrite{
+			Source:     r.source,
+			Registry:   registry,
+			Value:      value,
+			Original:   original,
+			Identifier: identifier,
+			Methods:    methods,
+		})
+	}
+}
+
+/*
+Warn records the input message, which describes a tolerated irregularity
+found upon the input line number.
+*/
+func (r *ImportReport) Warn(line int, msg string) {
+	if !r.IsZero() {
+		r.Warnings = append(r.Warnings, ImportWarning{
+			Source:  r.source,
+			Line:    line,
+			Message: msg,
+		})
+	}
+}
+
+/*
+Copy returns a deep copy of the receiver instance, which is unaffected by any
+subsequent import.
+*/
+func (r *ImportReport) Copy() (c *ImportReport) {
+	if r.IsZero() {
+		return
+	}
+
+	c = &ImportReport{
+		Skipped:  append(make([]SkippedRecord, 0, len(r.Skipped)), r.Skipped...),
+		Rewrites: make([]IdentifierRewrite, len(r.Rewrites)),
+		Warnings: append(make([]ImportWarning, 0, len(r.Warnings)), r.Warnings...),
+		source:   r.source,
+	}
+
+	for i, rw := range r.Rewrites {
+		rw.Methods = append([]string(nil), rw.Methods...)
+		c.Rewrites[i] = rw
+	}
+
+	return
+}
+
+/*
+Counts returns the number of skipped records and identifier rewrites for
+each source present within the receiver instance.
+*/
+func (r *ImportReport) Counts() (counts map[string]SourceCounts) {
+	counts = make(map[string]SourceCounts)
+	if r.IsZero() {
+		return
+	}
+
+	for _, skip := range r.Skipped {
+		c := counts[skip.Source]
+		c.Skipped++
+		counts[skip.Source] = c
+	}
+
+	for _, rw := range r.Rewrites {
+		c := counts[rw.Source]
+		c.Rewrites++
+		counts[rw.Source] = c
+	}
+
+	for _, warn := range r.Warnings {
+		c := counts[warn.Source]
+		c.Warnings++
+		counts[warn.Source] = c
+	}
+
+	return
+}
@@ -0,0 +1,221 @@
+package common
+
+/*
+search.go implements the full-text index of registration descriptions,
+information and registrant names.
+*/
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/oid-directory/go-radir"
+)
+
+/*
+Field weights applied to each token found within a registration. Registrant
+names are weighted most heavily, as they are the most likely subject of any
+query (e.g.: "which enterprise number does Acme Corp hold?").
+*/
+const (
+	weightRegistrant  float64 = 3 // registrant O and CN
+	weightDescription float64 = 2 // registration description
+	weightEmail       float64 = 1 // registrant email
+	weightInfo        float64 = 1 // supplemental information
+)
+
+/*
+SearchResult contains a single registration matched by [DIT.Search] alongside
+its score. Higher scores denote better matches.
+*/
+type SearchResult struct {
+	Registration *radir.Registration
+	Score        float64
+}
+
+/*
+textIndex maps tokens to the registrations whose text contains them.
+*/
+type textIndex struct {
+	docs     []*radir.Registration
+	terms    [][]string                 // doc -> tokens
+	postings map[string]map[int]float64 // token -> doc -> weighted frequency
+	doc      map[*radir.Registration]int
+}
+
+func newTextIndex() *textIndex {
+	return &textIndex{
+		postings: make(map[string]map[int]float64),
+		doc:      make(map[*radir.Registration]int),
+	}
+}
+
+/*
+tokenize returns the lower-case words present within the input string value.
+*/
+func tokenize(s string) []string {
+	return strings.FieldsFunc(lc(s), func(c rune) bool {
+		return !(unicode.IsLetter(c) || unicode.IsDigit(c))
+	})
+}
+
+/*
+update indexes the text of each of the input *[radir.Registration] instances,
+replacing that which was previously indexed, if any.
+*/
+func (r *textIndex) update(regs []*radir.Registration, dit *DIT) {
+	for _, reg := range regs {
+		r.add(reg, dit)
+	}
+}
+
+/*
+add indexes the text of the input *[radir.Registration].
+*/
+func (r *textIndex) add(reg *radir.Registration, dit *DIT) {
+	doc, found := r.doc[reg]
+	if found {
+		for _, tok := range r.terms[doc] {
+			if delete(r.postings[tok], doc); len(r.postings[tok]) == 0 {
+				delete(r.postings, tok)
+			}
+		}
+		r.terms[doc] = nil
+	} else {
+		doc = len(r.docs)
+		r.doc[reg] = doc
+		r.docs = append(r.docs, reg)
+		r.terms = append(r.terms, nil)
+	}
+
+	r.put(doc, reg.Description(), weightDescription)
+	for _, info := range reg.Supplement().Info() {
+		r.put(doc, info, weightInfo)
+	}
+
+	var cas []*radir.CurrentAuthority
+	if dit.profile.Dedicated() {
+		for _, dn := range reg.X660().CurrentAuthorities() {
+			if athy := dit.registrant(dn); !athy.IsZero() {
+				cas = append(cas, athy.CurrentAuthority())
+			}
+		}
+	} else if dit.profile.Combined() {
+		cas = append(cas, reg.X660().CombinedCurrentAuthority())
+	}
+
+	for _, ca := range cas {
+		if ca != nil {
+			r.put(doc, ca.O(), weightRegistrant)
+			r.put(doc, ca.CN(), weightRegistrant)
+			r.put(doc, ca.Email(), weightEmail)
+		}
+	}
+}
+
+func (r *textIndex) put(doc int, text string, weight float64) {
+	for _, tok := range tokenize(text) {
+		post, found := r.postings[tok]
+		if !found {
+			post = make(map[int]float64)
+			r.postings[tok] = post
+		}
+		if _, found = post[doc]; !found {
+			r.terms[doc] = append(r.terms[doc], tok)
+		}
+		post[doc] += weight
+	}
+}
+
+/*
+Search returns up to limit [SearchResult] instances whose text contains every
+word present within the input query, ordered by descending score. A limit of
+zero or less imposes no limit.
+
+Text consists of the description and information of each registration, as
+well as the organization, common name and email address of its registrants.
+Matching is case-insensitive and operates on whole words.
+
+If any bases are specified, only registrations at or beneath one of them are
+returned. See [DIT.Resolve] for the supported base syntaxes.
+*/
+func (r *DIT) Search(query string, limit int, bases ...string) (results []SearchResult, err error) {
+	if r.IsZero() || r.text == nil {
+		return
+	}
+
+	var dots []string
+	for _, base := range bases {
+		reg := r.Resolve(base)
+		if reg.IsZero() {
+			err = NotFoundError{Value: base}
+			return
+		}
+		dots = append(dots, reg.X680().DotNotation())
+	}
+
+	toks := tokenize(query)
+	if len(toks) == 0 {
+		return
+	}
+
+	idx := r.text
+	total := float64(len(idx.docs))
+	scores := make(map[int]float64)
+	for i, tok := range toks {
+		post := idx.postings[tok]
+		if len(post) == 0 {
+			return // every word must match
+		}
+
+		idf := math.Log(1 + total/float64(len(post)))
+		next := make(map[int]float64, len(post))
+		for doc, freq := range post {
+			if score, found := scores[doc]; found || i == 0 {
+				next[doc] = score + freq*idf
+			}
+		}
+		scores = next
+	}
+
+	for doc, score := range scores {
+		reg := idx.docs[doc]
+		if len(dots) == 0 || beneath(reg.X680().DotNotation(), dots) {
+			results = append(results, SearchResult{Registration: reg, Score: score})
+		}
+	}
+
+	// Rank by score, and thereafter by index order.
+	order := make(map[*radir.Registration]int, len(results))
+	for doc := range scores {
+		order[idx.docs[doc]] = doc
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return order[results[i].Registration] < order[results[j].Registration]
+	})
+
+	if 0 < limit && limit < len(results) {
+		results = results[:limit]
+	}
+
+	return
+}
+
+/*
+beneath returns a Boolean value indicative of the input dotNotation being
+equal to, or subordinate to, any of the input bases.
+*/
+func beneath(dot string, bases []string) bool {
+	for _, base := range bases {
+		if dot == base || hasPfx(dot, base+`.`) {
+			return true
+		}
+	}
+
+	return false
+}
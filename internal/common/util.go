@@ -32,7 +32,9 @@ var (
 	eq        func(string, string) bool           = strings.EqualFold
 	split     func(string, string) []string       = strings.Split
 	join      func([]string, string) string       = strings.Join
+	fields    func(string) []string               = strings.Fields
 	sidx      func(string, string) int            = strings.Index
+	lidx      func(string, string) int            = strings.LastIndex
 	idxr      func(string, rune) int              = strings.IndexRune
 	trimS     func(string) string                 = strings.TrimSpace
 	trimL     func(string, string) string         = strings.TrimLeft
@@ -42,6 +44,7 @@ var (
 	hasSfx    func(string, string) bool           = strings.HasSuffix
 	repeat    func(string, int) string            = strings.Repeat
 	atoi      func(string) (int, error)           = strconv.Atoi
+	itoa      func(int) string                    = strconv.Itoa
 	rplc      func(string, string, string) string = strings.ReplaceAll
 	open      func(string) (*os.File, error)      = os.Open
 	ctns      func(string, string) bool           = strings.Contains
@@ -6,7 +6,8 @@ Numbers (PEN) Registry.
 */
 
 import (
-	"fmt"
+	"bufio"
+	"io"
 
 	"github.com/oid-directory/go-radir"
 	"github.com/oid-directory/go-radit/internal/common"
@@ -17,6 +18,17 @@ const (
 	entASNPfx        = `{iso(1) identified-organization(3) dod(6) internet(1) private(4) enterprise(1)`
 	entDotPfx        = `1.3.6.1.4.1`
 	entIRIPfx        = `/ISO/Identified-Organization/6/1/4/1/`
+	penRegistryID    = `enterprise-numbers`
+)
+
+/*
+PEN Registry entry fields, as determined by the indentation of each line.
+*/
+const (
+	penDecimal = iota // no indentation
+	penOrg            // two (2) spaces
+	penContact        // four (4) spaces
+	penEmail          // six (6) spaces
 )
 
 /*
@@ -24,10 +36,127 @@ penRegistry facilitates storage and interaction with any number of [PEN]
 instances previously parsed from IANA's PEN Registry.
 */
 type penRegistry struct {
-	Numbers []pen
+	Header   penHeader
+	Numbers  []pen
+	problems []penProblem
 	*common.DIT
 }
 
+/*
+penProblem describes an irregularity found while parsing the PEN Registry
+in lenient mode. Problems are held until the parsed content is applied to
+the underlying *[common.DIT], at which time they are written to its report.
+*/
+type penProblem struct {
+	line    int
+	decimal string // offending enterprise number, if known
+	msg     string
+	skip    bool // the offending entry was not imported
+}
+
+/*
+penHeader implements the preamble of IANA's PEN Registry, which precedes
+the first enterprise number.
+*/
+type penHeader struct {
+	Updated string // e.g.: 2026-06-23
+	Prefix  string // e.g.: 1.3.6.1.4.1
+	Name    string // e.g.: iso.org.dod.internet.private.enterprise
+	URI     string // e.g.: https://www.iana.org/assignments/enterprise-numbers.txt
+}
+
+/*
+penLegend contains the column legend which concludes the PEN Registry
+header, in order of appearance.
+*/
+var penLegend []string = []string{
+	`Decimal`,
+	`| Organization`,
+	`| | Contact`,
+	`| | | Email`,
+	`| | | |`,
+}
+
+/*
+parsePENHeader returns a [penHeader] instance alongside the number of lines
+consumed and an error following an attempt to read the PEN Registry header
+from the input *[bufio.Scanner] instance. Upon success, the scanner is left
+positioned upon the final line of the column legend.
+
+An error is returned if the column legend is either absent or malformed,
+or if the prefix does not describe the "enterprise" arc (1.3.6.1.4.1).
+*/
+func parsePENHeader(scanner *bufio.Scanner) (hdr penHeader, lines int, err error) {
+	var legend int // number of legend lines matched thus far
+
+	for legend < len(penLegend) && scanner.Scan() {
+		lines++
+		line := trimS(scanner.Text())
+
+		if legend > 0 {
+			if line != penLegend[legend] {
+				err = mkerr("Unrecognized PEN registry layout at line " + itoa(lines) +
+					": expected '" + penLegend[legend] + "', got '" + line + "'")
+				return
+			}
+			legend++
+			continue
+		}
+
+		switch {
+		case line == penLegend[0]:
+			legend++
+		case hasPfx(line, `(last updated `):
+			hdr.Updated = trimS(trimR(line[len(`(last updated `):], `)`))
+		case hasPfx(line, `Prefix:`):
+			// e.g.: Prefix: iso.org.dod.internet.private.enterprise (1.3.6.1.4.1)
+			pfx := trimS(line[len(`Prefix:`):])
+			if idx := idxr(pfx, '('); idx != -1 {
+				hdr.Name = trimS(pfx[:idx])
+				hdr.Prefix = trimS(trimR(pfx[idx+1:], `)`))
+			}
+		case hasPfx(line, `This file is `):
+			hdr.URI = trimS(line[len(`This file is `):])
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return
+	} else if legend < len(penLegend) {
+		err = mkerr("Unrecognized PEN registry layout: column legend not found after " +
+			itoa(lines) + " lines")
+	} else if hdr.Prefix != entDotPfx {
+		err = mkerr("Unrecognized PEN registry prefix '" + hdr.Prefix +
+			"'; expected " + entDotPfx)
+	}
+
+	return
+}
+
+/*
+apply records the contents of the receiver instance upon the input
+*[radir.Registration] instance, which is the "enterprise" parent of all
+PENs.
+*/
+func (r penHeader) apply(parent *radir.Registration) {
+	if parent.X680().Identifier() == "" {
+		if sp := split(r.Name, `.`); len(sp) > 0 && sp[len(sp)-1] != "" {
+			parent.X680().SetIdentifier(sp[len(sp)-1])
+		}
+	}
+
+	// Values already present, such as those of a previous
+	// import, are not written again.
+	sup := parent.Supplement()
+	if info := `PEN Registry last updated ` + r.Updated; r.Updated != "" && !hasValue(sup.Info(), info) {
+		sup.SetInfo(info)
+	}
+
+	if uri := r.URI + ` PEN Registry`; r.URI != "" && !hasValue(sup.URI(), uri) {
+		sup.SetURI(uri)
+	}
+}
+
 /*
 pen, or Private Enterprise Number, implements any single registered
 enterprise number.
@@ -62,6 +191,18 @@ func (r *penRegistry) unmarshal() (err error) {
 		err = mkerr("Missing 1.3.6.1.4.1 parent; DIT must be primed before use")
 		return
 	}
+	r.Header.apply(parent)
+	r.DIT.Touch(parent)
+
+	report := r.DIT.Report()
+	for _, p := range r.problems {
+		if p.skip {
+			report.Skip(penRegistryID, p.decimal, `line `+itoa(p.line)+`: `+p.msg)
+		} else {
+			report.Warn(p.line, p.msg)
+		}
+	}
+	r.problems = nil
 
 	dnFunc := radir.DotNotToDN3D
 	if prof.Model() == radir.TwoDimensional {
@@ -78,6 +219,7 @@ func (r *penRegistry) unmarshal() (err error) {
 		}
 
 		child := parent.NewChild(itoa(ent.Decimal), ``)
+		r.DIT.Touch(child)
 		child.SetDN(child.X680().DotNotation(), dnFunc)
 		if err = ent.handleRegistrant(child, r.DIT); err != nil {
 			break
@@ -96,7 +238,7 @@ func (r *penRegistry) unmarshal() (err error) {
 
 func (r *penRegistry) loadJesseCoretta() {
 	for _, j := range JesseOID {
-		r.DIT.ISO().Allocate(j)
+		r.DIT.Touch(r.DIT.ISO().Allocate(j))
 	}
 }
 
@@ -149,69 +291,238 @@ func (r pen) handleRegistrant(child *radir.Registration, dit *common.DIT) (err e
 
 /*
 LoadPENRegistry returns an error following an attempt to parse the input
-filename, which is expected to refer to an UNMODIFIED copy of IANA's
-[PEN Numbers Text Registry].
+[io.Reader] instance, which is expected to supply an UNMODIFIED copy of
+IANA's [PEN Numbers Text Registry].
 
 Be advised: the text registry is a LARGE file; do not click on the link
 needlessly.
 
 [PEN Numbers Text Registry]: https://www.iana.org/assignments/enterprise-numbers.txt
 */
-func LoadPENRegistry(r *common.DIT, filename string) error {
-	if r.IsZero() {
+func LoadPENRegistry(r *common.DIT, src io.Reader) (err error) {
+	if r.IsZero() || src == nil {
 		return nilInstanceErr
 	}
 
-	f, err := open(filename)
-	if err != nil {
-		return err
+	var apply common.Applier
+	if apply, err = ParsePENRegistry(src, r.Options()); err == nil {
+		err = apply(r)
 	}
-	defer f.Close()
 
-	scanner := newScan(f)
+	return
+}
+
+/*
+ParsePENRegistry returns a [common.Applier] alongside an error following an
+attempt to parse the input [io.Reader] instance, which is expected to supply
+an UNMODIFIED copy of IANA's PEN Registry as described for [LoadPENRegistry].
+
+No *[common.DIT] is accessed until the returned [common.Applier] is called,
+allowing multiple sources to be parsed concurrently.
+*/
+func ParsePENRegistry(src io.Reader, opts common.ImportOptions) (apply common.Applier, err error) {
+	if src == nil {
+		err = nilInstanceErr
+		return
+	}
 
-	// TODO :: instead of skipping these lines
-	// we should use them as seeding for new
-	// Registration instances.
-	skipLines := 16
-	for i := 0; i < skipLines; i++ {
-		scanner.Scan()
+	ents := &penRegistry{Numbers: make([]pen, 0)}
+	if err = ents.parse(src, opts.StrictPEN); err != nil {
+		return
 	}
 
-	var (
-		ents *penRegistry = &penRegistry{
-			Numbers: make([]pen, 0),
-			DIT:     r,
+	apply = func(r *common.DIT) error {
+		if r.IsZero() {
+			return nilInstanceErr
 		}
-		ent pen
-	)
-
-	for scanner.Scan() {
-		if line := scanner.Text(); trimS(line) != "" {
-			switch {
-			case ent.Decimal == -1:
-				fmt.Sscanf(line, "%d", &ent.Decimal)
-				continue
-			case ent.Name == "":
-				ent.Name = trimS(line)
-				continue
-			case ent.Contact == "":
-				ent.Contact = trimS(line)
-				continue
-			case ent.Email == "":
-				ent.Email = trimS(line)
-			}
+
+		ents.DIT = r
+		return ents.unmarshal()
+	}
+
+	return
+}
+
+/*
+penParser implements a line-oriented state machine for the parsing of the
+PEN Registry entries which follow the header.
+
+In strict mode, the first irregularity encountered is returned as an error.
+In lenient mode, irregularities are recorded as problems, and any entry which
+cannot be salvaged is skipped.
+*/
+type penParser struct {
+	reg    *penRegistry
+	strict bool
+	line   int  // current line number
+	ent    *pen // entry currently being read, if any
+	at     int  // line number of the current entry's decimal
+	last   int  // last decimal accepted
+	orphan bool // skip field lines until the next decimal
+}
+
+/*
+parse returns an error following an attempt to read the header and all
+entries of the PEN Registry from src into the receiver instance.
+*/
+func (r *penRegistry) parse(src io.Reader, strict bool) (err error) {
+	scanner := newScan(src)
+
+	p := &penParser{reg: r, strict: strict, last: -1}
+	if r.Header, p.line, err = parsePENHeader(scanner); err != nil {
+		return
+	}
+
+	for err == nil && scanner.Scan() {
+		p.line++
+		err = p.parseLine(scanner.Text())
+	}
+
+	if err == nil {
+		if err = scanner.Err(); err == nil {
+			err = p.flush()
+		}
+	}
+
+	return
+}
+
+/*
+problem returns an error describing the input message if the receiver is
+in strict mode. Otherwise, the message is recorded as a problem with the
+registry, and nil is returned.
+*/
+func (r *penParser) problem(line int, decimal, msg string, skip bool) (err error) {
+	if r.strict {
+		err = mkerr("PEN registry line " + itoa(line) + ": " + msg)
+	} else {
+		r.reg.problems = append(r.reg.problems, penProblem{
+			line:    line,
+			decimal: decimal,
+			msg:     msg,
+			skip:    skip,
+		})
+	}
+
+	return
+}
+
+func (r *penParser) parseLine(raw string) (err error) {
+	raw = trimR(raw, "\r")
+	text := trimS(raw)
+	if text == "" || text == `End of Document` {
+		return
+	}
+
+	var field int
+	switch indent := len(raw) - len(trimL(raw, ` `)); {
+	case indent == 0:
+		field = penDecimal
+	case indent < 4:
+		field = penOrg
+	case indent < 6:
+		field = penContact
+	default:
+		field = penEmail
+	}
+
+	if field == penDecimal {
+		if err = r.flush(); err == nil {
+			err = r.begin(text)
 		}
+		return
+	}
 
-		if ent.Decimal != -1 {
-			ents.Numbers = append(ents.Numbers, ent)
-			ent = pen{Decimal: -1}
+	if r.ent == nil {
+		if !r.orphan {
+			r.orphan = true
+			err = r.problem(r.line, ``, `unexpected content outside of an entry: '`+text+`'`, false)
 		}
+		return
+	}
+
+	var dest *string
+	switch field {
+	case penOrg:
+		dest = &r.ent.Name
+	case penContact:
+		dest = &r.ent.Contact
+	case penEmail:
+		// Email addresses are obfuscated by way
+		// of an ampersand in place of com-at.
+		text = rplc(text, `&`, `@`)
+		dest = &r.ent.Email
+	}
+
+	if *dest != "" {
+		err = r.problem(r.line, itoa(r.ent.Decimal), `duplicate field line: '`+text+`'`, false)
+	} else {
+		*dest = text
 	}
 
-	if err = scanner.Err(); err == nil {
-		err = ents.unmarshal()
+	return
+}
+
+/*
+begin returns an error following an attempt to start a new entry using the
+input decimal string.
+*/
+func (r *penParser) begin(text string) (err error) {
+	dec, aerr := atoi(text)
+	switch {
+	case aerr != nil || dec < 0:
+		err = r.problem(r.line, text, `invalid decimal '`+text+`'`, true)
+	case dec <= r.last:
+		err = r.problem(r.line, text, `decimal `+text+` does not exceed preceding decimal `+
+			itoa(r.last), true)
+	default:
+		r.ent = &pen{Decimal: dec}
+		r.at = r.line
+		r.last = dec
+		r.orphan = false
+		return
 	}
 
-	return err
+	// Discard the field lines of the
+	// rejected entry silently.
+	r.ent, r.orphan = nil, true
+
+	return
+}
+
+/*
+flush returns an error following an attempt to validate and store the entry
+currently being read, if any.
+*/
+func (r *penParser) flush() (err error) {
+	ent := r.ent
+	if ent == nil {
+		return
+	}
+	r.ent = nil
+
+	dec := itoa(ent.Decimal)
+	if ent.Name == "" {
+		err = r.problem(r.at, dec, `missing organization line for decimal `+dec, true)
+		return
+	}
+
+	for _, missing := range []struct {
+		Field string
+		Name  string
+	}{
+		{ent.Contact, `contact`},
+		{ent.Email, `email`},
+	} {
+		if missing.Field == "" {
+			if err = r.problem(r.at, dec, `missing `+missing.Name+
+				` line for decimal `+dec, false); err != nil {
+				return
+			}
+		}
+	}
+
+	r.reg.Numbers = append(r.reg.Numbers, *ent)
+
+	return
 }
@@ -3,6 +3,7 @@ package iso
 import (
 	"encoding/xml"
 	"fmt"
+	"io"
 
 	"github.com/oid-directory/go-radir"
 	"github.com/oid-directory/go-radir/oid"
@@ -11,18 +12,32 @@ import (
 
 /*
 SMINumbers implements the top-level structure of the IANA SMI Numbers registry.
+
+Instances of this type are not populated wholesale; rather, the registry is
+decoded token-by-token (see smidec.go) and each of the resulting events is
+applied to the underlying *[common.DIT] as it arrives.
 */
 type smiRegistry struct {
-	XMLName     xml.Name   `xml:"registry"`
-	XMLNS       string     `xml:"xmlns,attr"`
-	Title       string     `xml:"title"`
-	ID          string     `xml:"id,attr"`
-	Updated     string     `xml:"updated"`
-	Note        []inner    `xml:"note"`
-	People      []person   `xml:"people>person"`
-	Registries  registries `xml:"registry"`
+	People      []person `xml:"people>person"`
 	*common.DIT `xml:"-"`
 	people      map[string]*radir.Registrant
+	opened      []*registry // in order of opening, for expert processing
+	links       []smiLink   // deferred registrant associations
+}
+
+/*
+smiLink implements a deferred association between a *[radir.Registration]
+and one or more registrants. Because the <people> element appears at the
+END of IANA's XML registries, such associations cannot be resolved until
+the entire registry has been read.
+
+If regi is non-nil, reg is associated with each of the experts gathered by
+regi. Otherwise reg is associated with the <person> identified by person.
+*/
+type smiLink struct {
+	reg    *radir.Registration
+	regi   *registry
+	person string
 }
 
 /*
@@ -39,11 +54,14 @@ type registry struct {
 	Expert      inner      `xml:"expert"`
 	Note        []inner    `xml:"note"`
 	XRef        []xref     `xml:"xref"`
-	Registries  registries `xml:"registry"`
-	Records     records    `xml:"record"`
 	Footnote    []footnote `xml:"footnote"`
 	smireg      *smiRegistry
 	experts     []*radir.Registrant
+	sup         *registry           // enclosing registry, if any
+	depth       int                 // nesting depth; one (1) for top-level registries
+	parent      *radir.Registration // allocated registration, if any
+	sorted      bool                // parent children have been sorted
+	notes       []noteLink          // deferred footnote associations
 }
 
 /*
@@ -57,11 +75,6 @@ type inner struct {
 	FullText string `xml:"-"`
 }
 
-/*
-Registries implements the slice form of SMI Numbers *[Registry] instances.
-*/
-type registries []*registry
-
 /*
 footnote implements the "footnote" SMI Numbers XML element.
 */
@@ -97,11 +110,6 @@ type record struct {
 	*common.DIT `xml:"-"`
 }
 
-/*
-records implements the slice form of SMI Numbers [record] instances.
-*/
-type records []record
-
 /*
 xref implements the "xref" SMI Numbers XML element, which is used to
 house references to documents, sites and other resources relevant to
@@ -166,22 +174,40 @@ func (r record) processValue() (dot, number, rangeTerminus string, err error) {
 	return
 }
 
-func (r *record) processIdentifier(dot string) (identifier string) {
+/*
+processIdentifier returns the identifier to be used for the receiver, along
+with the methods by which it was derived, in the order applied, if it differs
+from the verbatim record name. A zero identifier indicates the number form
+should be used.
+*/
+func (r *record) processIdentifier(dot string) (identifier string, methods []string) {
 	if !common.IsNumber(r.Name) {
 		if identifier = r.Name; identifier == "" {
 			var found bool
-			identifier, found = patchMissingName(dot, r.Value)
-			if !found && len(r.Description) > 0 {
+			if identifier, found = patchMissingName(dot, r.Value); found {
+				methods = append(methods, common.RewritePatched)
+			} else if len(r.Description) > 0 {
 				identifier = r.Description
+				methods = append(methods, common.RewriteDescription)
 			}
 		}
 
-		if identifier = legalizeIdentifier(identifier); !oid.IsNameForm(identifier) {
+		if legal := legalizeIdentifier(identifier); !oid.IsNameForm(legal) {
 			if alt := legalizeIdentifier(r.Description); oid.IsNameForm(alt) {
+				if identifier != r.Description {
+					methods = append(methods, common.RewriteDescription)
+				}
+				if alt != r.Description {
+					methods = append(methods, common.RewriteLegalized)
+				}
 				identifier = alt
 			} else {
 				identifier = ``
+				methods = append(methods, common.RewriteNumberForm)
 			}
+		} else if legal != identifier {
+			identifier = legal
+			methods = append(methods, common.RewriteLegalized)
 		}
 	} else {
 		r.Name = ""
@@ -190,30 +216,6 @@ func (r *record) processIdentifier(dot string) (identifier string) {
 	return
 }
 
-/*
-NumRegistries returns the integer number of [registry] instances found
-within the receiver instance.
-*/
-func (r registries) NumRegistries() int {
-	return len(r)
-}
-
-/*
-NumRegistries returns the integer number of [registry] instances found
-within the receiver instance.
-*/
-func (r smiRegistry) NumRegistries() int {
-	return len(r.Registries)
-}
-
-/*
-NumRecords returns the integer number of [record] instances found within
-the receiver instance.
-*/
-func (r registry) NumRecords() int {
-	return len(r.Records)
-}
-
 func errNotEoF(err error) (notEof bool) {
 	if err != nil {
 		notEof = err != eof
@@ -304,62 +306,135 @@ func descriptionAndOID(descr string) (desc, dot string) {
 	return
 }
 
-func (r records) unmarshal(smi *smiRegistry, parent *radir.Registration) {
-	dedi := smi.DIT.Profile().Dedicated()
-	comb := smi.DIT.Profile().Combined()
+func (r record) unmarshal(regi *registry) {
+	var (
+		smi    *smiRegistry         = regi.smireg
+		parent *radir.Registration  = regi.parent
+		report *common.ImportReport = smi.DIT.Report()
+	)
 
-	for i := 0; i < len(r); i++ {
-		rec := r[i]
+	_, number, rangeTerm, err := r.processValue()
+	obsolete := r.obsolete()
+	if err != nil {
+		report.Skip(regi.ID, r.Value, err.Error())
+		return
+	} else if obsolete && !smi.DIT.Options().KeepObsolete {
+		report.Skip(regi.ID, r.Value, `obsolete`)
+		return
+	}
+
+	identifier, methods := r.processIdentifier(parent.X680().DotNotation())
+	if len(methods) > 0 {
+		report.Rewrite(regi.ID, r.Value, r.Name, identifier, methods...)
+	}
+
+	if len(identifier) == 0 {
+		identifier = number
+	}
 
-		_, number, rangeTerm, err := rec.processValue()
-		if err != nil || rec.obsolete() {
-			continue
+	if child := parent.Children().Get(number); child.IsZero() {
+		child = parent.NewChild(number, identifier)
+		smi.DIT.Touch(child)
+		if rangeTerm != "" {
+			child.Supplement().SetRange(rangeTerm)
 		}
 
-		identifier := rec.processIdentifier(parent.X680().DotNotation())
-		if len(identifier) == 0 {
-			identifier = number
+		if ctns(child.X680().Identifier(), `.`) {
+			child.X680().SetIdentifier(identifier)
 		}
 
-		if child := parent.Children().Get(number); child.IsZero() {
-			child = parent.NewChild(number, identifier)
-			if rangeTerm != "" {
-				child.Supplement().SetRange(rangeTerm)
-			}
+		if obsolete {
+			child.Supplement().SetStatus(`OBSOLETE`)
+		}
 
-			if ctns(child.X680().Identifier(), `.`) {
-				child.X680().SetIdentifier(identifier)
+		// Process the XRef into one of a few possible
+		// forms, such as uri, rfc, person, et al.
+		for _, xr := range r.XRef {
+			xr.process(child, smi)
+			if xr.Type == "person" {
+				// Defer until all <person> elements
+				// have been read.
+				smi.links = append(smi.links, smiLink{
+					reg:    child,
+					person: xr.Data,
+				})
+			} else if obsolete && xr.Type == "note" && xr.Data != "" {
+				// Footnotes follow the records of a
+				// registry, so defer until closure.
+				regi.notes = append(regi.notes, noteLink{
+					reg:    child,
+					anchor: xr.Data,
+				})
 			}
+		}
+	}
+}
 
-			// Process the XRef into one of a few possible
-			// forms, such as uri, rfc, person, et al.
-			for _, xr := range rec.XRef {
-				xr.process(child, smi)
-				if xr.Type == "person" {
-					if athy, found := smi.people[xr.Data]; found {
-						cath := athy.CurrentAuthority()
-						if dedi {
-							child.X660().SetCurrentAuthorities(athy.DN())
-						} else if comb {
-							coauth := child.X660().CombinedCurrentAuthority()
-							coauth.SetEmail(cath.Email())
-							coauth.SetCN(cath.CN())
-							coauth.SetO(cath.O())
-							child.SetDescription(athy.Description())
-						}
-					}
+/*
+noteLink implements a deferred association between a *[radir.Registration]
+and the text of the <footnote> bearing the input anchor.
+*/
+type noteLink struct {
+	reg    *radir.Registration
+	anchor string
+}
+
+/*
+linkFootnotes writes the text of each footnote referenced by a deferred
+[noteLink] as information upon the referencing registration.
+*/
+func (r *registry) linkFootnotes() {
+	for _, nl := range r.notes {
+		for _, fn := range r.Footnote {
+			if fn.Anchor == nl.anchor {
+				if text := common.CondenseWHSP(common.RemoveNL(fn.Text)); text != "" {
+					nl.reg.Supplement().SetInfo(text)
 				}
+				break
 			}
 		}
 	}
 
-	// Sort children
-	parent.Children().SortByNumberForm()
+	r.notes = nil
+}
 
-	return
+/*
+link associates the registrant(s) described by the receiver instance with
+the underlying *[radir.Registration].
+*/
+func (r smiLink) link(smi *smiRegistry) {
+	if r.regi != nil {
+		for _, expert := range r.regi.experts {
+			r.reg.X660().SetCurrentAuthorities(expert.DN())
+		}
+		return
+	}
+
+	athy, found := smi.people[r.person]
+	if !found {
+		return
+	}
+
+	cath := athy.CurrentAuthority()
+	if smi.DIT.Profile().Dedicated() {
+		r.reg.X660().SetCurrentAuthorities(athy.DN())
+	} else if smi.DIT.Profile().Combined() {
+		coauth := r.reg.X660().CombinedCurrentAuthority()
+		coauth.SetEmail(cath.Email())
+		coauth.SetCN(cath.CN())
+		coauth.SetO(cath.O())
+		r.reg.SetDescription(athy.Description())
+	}
 }
 
-func (r *registry) unmarshalRecords() (err error) {
+/*
+open returns an error following an attempt to allocate the registration
+described by the receiver instance, to which its records are subsequently
+added. This is called once all of the descriptive elements of the registry
+have been read, and prior to the processing of any of its records or
+sub-registries.
+*/
+func (r *registry) open() (err error) {
 	if r.IsZero() {
 		return
 	}
@@ -386,12 +461,14 @@ func (r *registry) unmarshalRecords() (err error) {
 		ident  string
 		parent *radir.Registration = r.smireg.DIT.ISO().Allocate(oid)
 	)
+	r.smireg.DIT.Touch(parent)
 
 	// Process experts into registrant data
-	//r.processExperts(parent)
-	for _, expert := range r.experts {
-		parent.X660().SetCurrentAuthorities(expert.DN())
-	}
+	// once all registrants are known.
+	r.smireg.links = append(r.smireg.links, smiLink{
+		reg:  parent,
+		regi: r,
+	})
 
 	// Process the XRef into one of a few possible
 	// forms, such as uri, rfc, person, et al.
@@ -424,11 +501,22 @@ func (r *registry) unmarshalRecords() (err error) {
 	}
 
 	parent.X680().SetASN1Notation(buildASN1Not(sp, path))
-	r.Records.unmarshal(r.smireg, parent)
+	r.parent = parent
 
 	return
 }
 
+/*
+finishRecords sorts the children of the allocated registration, if any,
+once all records of the receiver instance have been processed.
+*/
+func (r *registry) finishRecords() {
+	if r.parent != nil && !r.sorted {
+		r.parent.Children().SortByNumberForm()
+		r.sorted = true
+	}
+}
+
 func (r *registry) unmarshalRecordNotes(parent *radir.Registration) (err error) {
 	for _, n := range r.Note {
 		clean := trimS(n.Text)
@@ -607,49 +695,67 @@ IsZero returns a Boolean value indicative of a nil receiver state.
 */
 func (r *registry) IsZero() bool { return r == nil }
 
-func (r *registry) unmarshal() (err error) {
-	if !r.IsZero() {
-		if err = r.unmarshalRecords(); err != nil {
-			return
+/*
+handle returns an error following an attempt to apply the input event to
+the receiver instance.
+*/
+func (r *smiRegistry) handle(ev smiEvent) (err error) {
+	switch ev.kind {
+	case smiOpen:
+		regi := ev.regi
+		regi.smireg = r
+		r.opened = append(r.opened, regi)
+
+		if regi.sup != nil {
+			// Records of the enclosing registry
+			// precede its sub-registries.
+			regi.sup.finishRecords()
 		}
 
-		for _, subregi := range r.Registries {
-			subregi.smireg = r.smireg
-			if err = subregi.unmarshal(); err != nil {
-				break
+		if regi.depth == 1 {
+			if k, found := missingRegistryURNs[regi.ID]; found {
+				regi.Description = missingRegistryURNs[k]
 			}
 		}
+
+		err = regi.open()
+
+		// Notes and xrefs are no longer needed.
+		regi.Note, regi.XRef = nil, nil
+	case smiRecord:
+		// Records of registries which bear no OID
+		// are not registrations, and are ignored.
+		if ev.regi.parent != nil {
+			ev.rec.unmarshal(ev.regi)
+		}
+	case smiClose:
+		ev.regi.finishRecords()
+		ev.regi.linkFootnotes()
+	case smiPeople:
+		r.People = append(r.People, ev.people...)
 	}
 
 	return
 }
 
 /*
-Unmarshal returns an error following an attempt to write the contents of
-the receiver into instances of *[radir.Registration] and (if appropriate)
-*[radir.Registrant].
+finish creates all registrants described within the registry, and resolves
+all deferred registrant associations. This is called once the entire
+registry has been read.
 */
-func (r *smiRegistry) unmarshal() (err error) {
-	if !r.IsZero() {
-		r.gatherRegistrants()
-
-		// Process all registries, descending into
-		// Records and (sub) Registries as needed.
-		for _, regi := range r.Registries {
-			regi.smireg = r
-			if k, found := missingRegistryURNs[regi.ID]; found {
-				regi.Description = missingRegistryURNs[k]
-			}
-
-			if err = regi.unmarshal(); err != nil {
-				break
-			}
-		}
+func (r *smiRegistry) finish() {
+	r.gatherRegistrants()
+	for _, link := range r.links {
+		link.link(r)
 	}
 
-	return
+	r.opened, r.links = nil, nil
 }
 
+/*
+gatherExperts creates a *[radir.Registrant] for each expert named by the
+receiver instance, unless one by the same name exists already.
+*/
 func (r *registry) gatherExperts() {
 	sp := split(r.Expert.Text, `,`)
 
@@ -676,11 +782,6 @@ func (r *registry) gatherExperts() {
 			}
 		}
 	}
-
-	for i := 0; i < len(r.Registries); i++ {
-		r.Registries[i].smireg = r.smireg
-		r.Registries[i].gatherExperts()
-	}
 }
 
 func (r *smiRegistry) gatherRegistrants() {
@@ -725,32 +826,102 @@ func (r *smiRegistry) gatherRegistrants() {
 		}
 	}
 
-	for _, regi := range r.Registries {
-		regi.smireg = r
+	// ... followed by all experts, in the
+	// order in which registries were read.
+	for _, regi := range r.opened {
 		regi.gatherExperts()
 	}
 }
 
 /*
 LoadSMIRegistry returns an error following an attempt to parse the input
-filename, which is expected to refer to an UNMODIFIED copy of IANA's
-[SMI-Numbers XML registry].
+[io.Reader] instance, which is expected to supply an UNMODIFIED copy of
+IANA's [SMI-Numbers XML registry] or [LDAP Parameters XML registry].
+
+The registry is decoded as a stream of tokens; each <registry> and <record>
+element is applied to the input *[common.DIT] as it arrives, and discarded
+thereafter.
 
 [SMI-Numbers XML registry]: https://www.iana.org/assignments/smi-numbers/smi-numbers.xml
+[LDAP Parameters XML registry]: https://www.iana.org/assignments/ldap-parameters/ldap-parameters.xml
 */
-func LoadSMIRegistry(r *common.DIT, filename string) (err error) {
-	var (
-		content []byte
-		smi     smiRegistry
-	)
+func LoadSMIRegistry(r *common.DIT, src io.Reader) (err error) {
+	if r.IsZero() || src == nil {
+		return nilInstanceErr
+	}
 
-	smi.people = make(map[string]*radir.Registrant, 0)
+	smi := newSMIRegistry(r)
+	if err = decodeSMI(src, smi.handle); err == nil {
+		smi.finish()
+	}
+
+	return
+}
+
+/*
+ParseSMIRegistry returns a [common.Applier] alongside an error following an
+attempt to decode the input [io.Reader] instance, which is expected to supply
+an UNMODIFIED copy of an IANA XML registry as described for [LoadSMIRegistry].
+
+Unlike [LoadSMIRegistry], the decoded content is retained in memory until the
+returned [common.Applier] is called. This allows multiple sources to be decoded
+concurrently, as no *[common.DIT] is accessed until that time.
+
+Note that every <registry> and <record> element is thereby held at once, such
+that memory use grows with the size of the registry rather than remaining
+bounded by the token stream. This function serves concurrent imports alone;
+sequential imports use [LoadSMIRegistry], which retains nothing once applied.
+*/
+func ParseSMIRegistry(src io.Reader, _ common.ImportOptions) (apply common.Applier, err error) {
+	if src == nil {
+		err = nilInstanceErr
+		return
+	}
+
+	var events []smiEvent
+	if err = decodeSMI(src, func(ev smiEvent) error {
+		events = append(events, ev)
+		return nil
+	}); err != nil {
+		return
+	}
 
-	if content, err = common.ReadBytes(filename); err == nil {
-		if err = xml.Unmarshal(content, &smi); !errNotEoF(err) {
-			smi.DIT = r
-			err = smi.unmarshal()
+	apply = func(r *common.DIT) (err error) {
+		if r.IsZero() {
+			return nilInstanceErr
 		}
+
+		smi := newSMIRegistry(r)
+		for i := 0; i < len(events) && err == nil; i++ {
+			err = smi.handle(events[i])
+		}
+
+		if err == nil {
+			smi.finish()
+		}
+
+		return
+	}
+
+	return
+}
+
+func newSMIRegistry(r *common.DIT) *smiRegistry {
+	return &smiRegistry{
+		DIT:    r,
+		people: make(map[string]*radir.Registrant, 0),
+	}
+}
+
+/*
+LegalizeIdentifier returns a legal X.680 identifier (name form) derived from
+the input value, or a zero string if no such identifier could be derived.
+Illegal values, such as "IEEE802.4", are altered in the same manner as are
+the names of SMI records during import.
+*/
+func LegalizeIdentifier(in string) (out string) {
+	if out = legalizeIdentifier(in); !oid.IsNameForm(out) {
+		out = ``
 	}
 
 	return
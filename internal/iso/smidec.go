@@ -0,0 +1,209 @@
+package iso
+
+/*
+smidec.go implements the token-streaming decoder used to read IANA's SMI
+Numbers and LDAP Parameters XML registries.
+*/
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+/*
+smiEvent kinds.
+*/
+const (
+	smiOpen   = iota // all descriptive elements of a registry have been read
+	smiRecord        // a record of a registry has been read
+	smiClose         // the end of a registry has been reached
+	smiPeople        // the <people> element has been read
+)
+
+/*
+smiEvent implements a single unit of SMI registry content, as produced by
+an [smiDecoder].
+*/
+type smiEvent struct {
+	kind   int
+	regi   *registry
+	rec    record
+	people []person
+}
+
+/*
+smiDecoder implements a token-streaming decoder of IANA's XML registries.
+
+Only the <registry> element currently being read -- and those enclosing
+it -- are held in memory. Records are emitted individually and are never
+accumulated. The descriptive elements of a registry (e.g.: <description>,
+<note>, <xref>) are expected to precede its records and sub-registries, as
+is the case with IANA's registries; an [smiOpen] event is emitted for each
+registry once the first of its records or sub-registries is encountered.
+*/
+type smiDecoder struct {
+	dec    *xml.Decoder
+	emit   func(smiEvent) error
+	stack  []*registry
+	inRoot bool
+	opened map[*registry]bool
+}
+
+/*
+decodeSMI returns an error following an attempt to decode the XML content
+read from src, passing each [smiEvent] to the emit closure as it arrives.
+*/
+func decodeSMI(src io.Reader, emit func(smiEvent) error) (err error) {
+	d := &smiDecoder{
+		dec:    xml.NewDecoder(src),
+		emit:   emit,
+		opened: make(map[*registry]bool),
+	}
+
+	for err == nil {
+		var token xml.Token
+		if token, err = d.dec.Token(); err == nil {
+			err = d.token(token)
+		}
+	}
+
+	if !errNotEoF(err) {
+		err = nil
+	}
+
+	return
+}
+
+/*
+top returns the *[registry] currently being read, or nil if no registry
+below the outermost has been encountered.
+*/
+func (r *smiDecoder) top() (top *registry) {
+	if len(r.stack) > 0 {
+		top = r.stack[len(r.stack)-1]
+	}
+
+	return
+}
+
+/*
+open emits an [smiOpen] event for regi, unless one has been emitted already.
+*/
+func (r *smiDecoder) open(regi *registry) (err error) {
+	if regi != nil && !r.opened[regi] {
+		r.opened[regi] = true
+		err = r.emit(smiEvent{kind: smiOpen, regi: regi})
+	}
+
+	return
+}
+
+func (r *smiDecoder) token(token xml.Token) (err error) {
+	top := r.top()
+
+	switch t := token.(type) {
+	case xml.StartElement:
+		switch {
+		case !r.inRoot:
+			// Expect the outermost <registry> element.
+			if t.Name.Local == `registry` {
+				r.inRoot = true
+			} else {
+				err = r.dec.Skip()
+			}
+		case t.Name.Local == `registry`:
+			if err = r.open(top); err == nil {
+				regi := &registry{sup: top, depth: len(r.stack) + 1}
+				for _, attr := range t.Attr {
+					if attr.Name.Local == `id` {
+						regi.ID = attr.Value
+					}
+				}
+				r.stack = append(r.stack, regi)
+			}
+		case top == nil:
+			err = r.rootElement(t)
+		default:
+			err = r.registryElement(t, top)
+		}
+	case xml.EndElement:
+		// Only registry end elements appear at this
+		// level, as all others are fully consumed by
+		// DecodeElement or Skip.
+		if t.Name.Local == `registry` && top != nil {
+			if err = r.open(top); err == nil {
+				r.stack = r.stack[:len(r.stack)-1]
+				delete(r.opened, top)
+				err = r.emit(smiEvent{kind: smiClose, regi: top})
+			}
+		}
+	}
+
+	return
+}
+
+/*
+rootElement returns an error following an attempt to decode the input child
+element of the outermost <registry> element. Only <people> is of interest;
+other elements, such as <title> and <note>, are not used.
+*/
+func (r *smiDecoder) rootElement(t xml.StartElement) (err error) {
+	if t.Name.Local != `people` {
+		err = r.dec.Skip()
+		return
+	}
+
+	var people struct {
+		Person []person `xml:"person"`
+	}
+	if err = r.dec.DecodeElement(&people, &t); err == nil {
+		err = r.emit(smiEvent{kind: smiPeople, people: people.Person})
+	}
+
+	return
+}
+
+/*
+registryElement returns an error following an attempt to decode the input
+child element of the input *[registry] instance.
+*/
+func (r *smiDecoder) registryElement(t xml.StartElement, top *registry) (err error) {
+	switch t.Name.Local {
+	case `record`:
+		if err = r.open(top); err == nil {
+			var rec record
+			if err = r.dec.DecodeElement(&rec, &t); err == nil {
+				err = r.emit(smiEvent{kind: smiRecord, regi: top, rec: rec})
+			}
+		}
+	case `description`:
+		err = r.dec.DecodeElement(&top.Description, &t)
+	case `title`:
+		err = r.dec.DecodeElement(&top.Title, &t)
+	case `updated`:
+		err = r.dec.DecodeElement(&top.Updated, &t)
+	case `registration_rule`:
+		err = r.dec.DecodeElement(&top.Rule, &t)
+	case `expert`:
+		err = r.dec.DecodeElement(&top.Expert, &t)
+	case `note`:
+		var n inner
+		if err = r.dec.DecodeElement(&n, &t); err == nil {
+			top.Note = append(top.Note, n)
+		}
+	case `xref`:
+		var xr xref
+		if err = r.dec.DecodeElement(&xr, &t); err == nil {
+			top.XRef = append(top.XRef, xr)
+		}
+	case `footnote`:
+		var fn footnote
+		if err = r.dec.DecodeElement(&fn, &t); err == nil {
+			top.Footnote = append(top.Footnote, fn)
+		}
+	default:
+		err = r.dec.Skip()
+	}
+
+	return
+}
@@ -45,6 +45,20 @@ var (
 
 func newBuilder() strings.Builder { return strings.Builder{} }
 
+/*
+hasValue returns a Boolean value indicative of the input value being present
+within the input slice.
+*/
+func hasValue(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+
+	return false
+}
+
 var (
 	eof            error = io.EOF
 	nilInstanceErr error = mkerr("Instance or receiver is nil; must initialize")
@@ -0,0 +1,157 @@
+package radit
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/oid-directory/go-radir"
+	"github.com/oid-directory/go-radit/internal/common"
+)
+
+/*
+Entry describes a single registration, as written by [RADIT.WriteJSON] and
+[RADIT.WriteJSONLines] and as read by the "jsonfile" importer. Children is
+only populated by [RADIT.WriteJSON].
+*/
+type Entry = common.Entry
+
+/*
+EntryRegistrant describes a single registrant of an [Entry]. Registrants are
+identified by DN, such that a registrant shared by several registrations is
+imported only once.
+*/
+type EntryRegistrant = common.EntryRegistrant
+
+/*
+jsonWriter wraps an [io.Writer] instance, tallying the number of bytes
+written by an underlying [json.Encoder].
+*/
+type jsonWriter struct {
+	countWriter
+	opts  WriteOptions
+	entry func(*radir.Registration) *Entry
+}
+
+/*
+WriteJSON writes the registrations present within the receiver instance to
+the input [io.Writer] instance as a single JSON array of nested [Entry]
+instances, one per selected root or base, returning the number of bytes
+written alongside an error, if any.
+
+The input [WriteOptions] are honored as described for [RADIT.WriteLDIF],
+save for Subentries, which has no meaning here. Registrants, if requested,
+are included within each [Entry]. Registrations omitted by the Filter are
+replaced by their children.
+
+The output may be loaded into another instance by way of the "jsonfile"
+key of [ImportList] or [ImportSources].
+*/
+func (r *RADIT) WriteJSON(w io.Writer, opts WriteOptions) (n int64, err error) {
+	jw, bases, unlock, err := r.prepareJSON(w, opts)
+	defer unlock()
+	if err != nil {
+		return
+	}
+
+	entries := make([]*Entry, 0)
+	for _, base := range bases {
+		entries = append(entries, jw.nest(base, 0)...)
+	}
+
+	enc := json.NewEncoder(jw)
+	enc.SetIndent(``, `  `)
+	err = enc.Encode(entries)
+	n = jw.n
+
+	return
+}
+
+/*
+WriteJSONLines writes the registrations present within the receiver instance
+to the input [io.Writer] instance as JSON Lines, one [Entry] per line in
+depth-first order, returning the number of bytes written alongside an error,
+if any. Unlike [RADIT.WriteJSON], the output is not assembled in memory.
+
+The input [WriteOptions] are honored as described for [RADIT.WriteJSON].
+*/
+func (r *RADIT) WriteJSONLines(w io.Writer, opts WriteOptions) (n int64, err error) {
+	jw, bases, unlock, err := r.prepareJSON(w, opts)
+	defer unlock()
+	if err != nil {
+		return
+	}
+
+	enc := json.NewEncoder(jw)
+	for i := 0; i < len(bases) && err == nil; i++ {
+		err = jw.lines(enc, bases[i])
+	}
+	n = jw.n
+
+	return
+}
+
+/*
+prepareJSON returns a *jsonWriter wrapping the input [io.Writer] alongside
+the values returned by [RADIT.prepare].
+*/
+func (r *RADIT) prepareJSON(w io.Writer, opts WriteOptions) (jw *jsonWriter, bases []*radir.Registration, unlock func(), err error) {
+	unlock = func() {}
+	if r.IsZero() {
+		err = errors.New("RADIT instance is nil, aborting write")
+		return
+	} else if w == nil {
+		err = errors.New("io.Writer instance is nil, aborting write")
+		return
+	}
+
+	if bases, unlock, err = r.prepare(opts); err == nil {
+		jw = &jsonWriter{
+			countWriter: countWriter{w: w},
+			opts:        opts,
+			entry:       r.dit.EntryWriter(opts.Registrants),
+		}
+	}
+
+	return
+}
+
+/*
+nest returns the nested [Entry] of the input registration, or those of its
+children if the registration is omitted by the Filter.
+*/
+func (r *jsonWriter) nest(reg *radir.Registration, depth int) (entries []*Entry) {
+	if reg.IsZero() {
+		return
+	}
+
+	omit, deep := skip(reg, depth, r.opts)
+
+	var children []*Entry
+	if !deep {
+		kids := reg.Children()
+		for i := 0; i < kids.Len(); i++ {
+			children = append(children, r.nest(kids.Index(i), depth+1)...)
+		}
+	}
+
+	if omit {
+		entries = children
+	} else {
+		e := r.entry(reg)
+		e.Children = children
+		entries = []*Entry{e}
+	}
+
+	return
+}
+
+/*
+lines encodes the [Entry] of the input registration, followed by those of
+its descendants, in depth-first order.
+*/
+func (r *jsonWriter) lines(enc *json.Encoder, reg *radir.Registration) error {
+	return walk(reg, r.opts, func(reg *radir.Registration) error {
+		return enc.Encode(r.entry(reg))
+	})
+}
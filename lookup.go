@@ -0,0 +1,161 @@
+package radit
+
+import (
+	"errors"
+
+	"github.com/oid-directory/go-radir"
+	"github.com/oid-directory/go-radit/internal/common"
+)
+
+/*
+ErrNotFound is matched, by way of [errors.Is], by the error returned from
+any lookup method when the requested registration does not exist.
+*/
+var ErrNotFound error = common.ErrNotFound
+
+/*
+NotFoundError describes a lookup value which is syntactically valid, but
+does not identify any registration present within a [RADIT] instance.
+*/
+type NotFoundError = common.NotFoundError
+
+/*
+Lookup returns the *[radir.Registration] identified by the input dotNotation
+value, such as "1.3.6.1.4.1.56521", alongside an error. If the value is valid
+but no such registration exists, the error shall be an instance of
+[NotFoundError].
+
+The return instance is that held by the receiver instance, and should not be
+modified while the receiver is shared with other goroutines.
+*/
+func (r *RADIT) Lookup(dot string) (*radir.Registration, error) {
+	return r.lookup(func(dit *common.DIT) (*radir.Registration, error) {
+		return dit.Lookup(dot)
+	})
+}
+
+/*
+LookupASN1 returns the *[radir.Registration] identified by the input ASN.1
+notation alongside an error. Each arc may be in NameAndNumber, number or
+name form, e.g.:
+
+	{iso(1) identified-organization(3) dod(6) internet(1) private(4)}
+	{iso identified-organization dod internet private}
+
+See [RADIT.Lookup] for details regarding the return values.
+*/
+func (r *RADIT) LookupASN1(asn string) (*radir.Registration, error) {
+	return r.lookup(func(dit *common.DIT) (*radir.Registration, error) {
+		return dit.LookupASN1(asn)
+	})
+}
+
+/*
+LookupIRI returns the *[radir.Registration] identified by the input OID-IRI
+alongside an error. Each arc may be a unicode label or a number form, e.g.:
+
+	/ISO/Identified-Organization/6/1/4/1
+
+See [RADIT.Lookup] for details regarding the return values.
+*/
+func (r *RADIT) LookupIRI(iri string) (*radir.Registration, error) {
+	return r.lookup(func(dit *common.DIT) (*radir.Registration, error) {
+		return dit.LookupIRI(iri)
+	})
+}
+
+/*
+LookupIdentifiers returns the *[radir.Registration] identified by the input
+path of X.680 identifiers, starting at a root, alongside an error. Number
+forms may be used in place of any identifier, e.g.:
+
+	r.LookupIdentifiers("iso", "identified-organization", "dod", "internet")
+
+See [RADIT.Lookup] for details regarding the return values.
+*/
+func (r *RADIT) LookupIdentifiers(ids ...string) (*radir.Registration, error) {
+	return r.lookup(func(dit *common.DIT) (*radir.Registration, error) {
+		return dit.LookupIdentifiers(ids...)
+	})
+}
+
+func (r *RADIT) lookup(funk func(*common.DIT) (*radir.Registration, error)) (reg *radir.Registration, err error) {
+	if r.IsZero() {
+		err = errors.New("RADIT instance is nil, aborting lookup")
+		return
+	}
+
+	r.dit.RLock()
+	defer r.dit.RUnlock()
+
+	return funk(r.dit)
+}
+
+/*
+MatchMode defines the manner in which names are matched by [RADIT.FindByName].
+See [MatchExact], [MatchFold] and [MatchPrefix].
+*/
+type MatchMode = common.MatchMode
+
+const (
+	MatchExact  = common.MatchExact  // exact, case-sensitive match
+	MatchFold   = common.MatchFold   // case-insensitive match
+	MatchPrefix = common.MatchPrefix // match names beginning with the input value
+)
+
+/*
+FindByName returns all *[radir.Registration] instances whose X.680 identifier
+or X.660 unicode value, such as "ifType" or "id-pe-acmeIdentifier", matches the
+input name according to the input [MatchMode]. Modes may be combined, e.g.:
+
+	regs := r.FindByName("pilot", MatchFold|MatchPrefix)
+
+As names are not unique, any number of instances may be returned. The index
+consulted by this method is updated following each Prime and Import call with
+the registrations allocated or altered thereby.
+*/
+func (r *RADIT) FindByName(name string, mode MatchMode) (regs []*radir.Registration) {
+	if !r.IsZero() {
+		r.dit.RLock()
+		defer r.dit.RUnlock()
+		regs = r.dit.FindByName(name, mode)
+	}
+
+	return
+}
+
+/*
+SearchResult contains a single registration matched by [RADIT.Search]
+alongside its score. Higher scores denote better matches.
+*/
+type SearchResult = common.SearchResult
+
+/*
+Search returns up to limit [SearchResult] instances, ranked by descending
+score, whose text contains every word of the input query. A limit of zero
+or less imposes no limit.
+
+Text consists of the description and information of each registration, as
+well as the organization, common name and email address of its registrants.
+Matching is case-insensitive and operates on whole words, e.g.:
+
+	results, err := r.Search("Acme Corp", 10, "1.3.6.1.4.1")
+
+If any bases are specified, such as a root name or the dotNotation of any
+registration, only registrations at or beneath one of them are returned. An
+instance of [NotFoundError] is returned if a base does not exist.
+
+As with [RADIT.FindByName], the index consulted by this method is updated
+following each Prime and Import call.
+*/
+func (r *RADIT) Search(query string, limit int, bases ...string) (results []SearchResult, err error) {
+	if r.IsZero() {
+		err = errors.New("RADIT instance is nil, aborting search")
+		return
+	}
+
+	r.dit.RLock()
+	defer r.dit.RUnlock()
+
+	return r.dit.Search(query, limit, bases...)
+}
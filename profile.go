@@ -0,0 +1,103 @@
+package radit
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/oid-directory/go-radit/internal/common"
+)
+
+/*
+ProfileConfig describes the layout of a DIT, namely its registration and
+registrant base DNs, its model and its registrants policy, such that each
+environment may keep its own layout under version control. See
+[LoadProfileConfig] and [NewFromConfig].
+*/
+type ProfileConfig = common.ProfileConfig
+
+/*
+DIT models, for use within [ProfileConfig].
+*/
+const (
+	Model2D = common.Model2D
+	Model3D = common.Model3D
+)
+
+/*
+Registrants policies, for use within [ProfileConfig].
+*/
+const (
+	PolicyDedicated = common.PolicyDedicated
+	PolicyCombined  = common.PolicyCombined
+)
+
+/*
+ReadProfileConfig returns an instance of [ProfileConfig] alongside an error
+following an attempt to read and validate the JSON or YAML content supplied
+by the input [io.Reader] instance. YAML content must consist of one "key:
+value" pair per line, e.g.:
+
+	# Production layout
+	registrationBase: ou=Registrations,o=rA
+	registrantBase: ou=Registrants,o=rA
+	model: 3D
+	registrantsPolicy: dedicated
+
+Unknown keys are rejected, and unspecified keys retain the values of the
+factory default DUA configuration.
+*/
+func ReadProfileConfig(src io.Reader) (cfg ProfileConfig, err error) {
+	if cfg, err = common.ParseProfileConfig(src); err == nil {
+		err = cfg.Validate()
+	}
+
+	return
+}
+
+/*
+LoadProfileConfig returns an instance of [ProfileConfig] alongside an error
+following an attempt to read and validate the named file. See
+[ReadProfileConfig] for details.
+*/
+func LoadProfileConfig(path string) (cfg ProfileConfig, err error) {
+	var f *os.File
+	if f, err = os.Open(path); err != nil {
+		return
+	}
+	defer f.Close()
+
+	if cfg, err = ReadProfileConfig(f); err != nil {
+		err = errors.New(path + ": " + err.Error())
+	}
+
+	return
+}
+
+/*
+NewFromConfig returns a new, unprimed instance of *[RADIT] bearing the DIT
+profile described by the input [ProfileConfig] alongside an error following
+validation of the configuration.
+*/
+func NewFromConfig(cfg ProfileConfig) (r *RADIT, err error) {
+	profile, err := cfg.Profile()
+	if err == nil {
+		r = New(profile)
+	}
+
+	return
+}
+
+/*
+NewFromFile returns a new, unprimed instance of *[RADIT] bearing the DIT
+profile described by the named JSON or YAML file alongside an error. This
+is a convenience wrapper of [LoadProfileConfig] and [NewFromConfig].
+*/
+func NewFromFile(path string) (r *RADIT, err error) {
+	var cfg ProfileConfig
+	if cfg, err = LoadProfileConfig(path); err == nil {
+		r, err = NewFromConfig(cfg)
+	}
+
+	return
+}
@@ -3,6 +3,10 @@ package radit
 import (
 	"bytes"
 	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
 
 	"github.com/oid-directory/go-radir"
 	"github.com/oid-directory/go-radit/internal/common"
@@ -11,6 +15,15 @@ import (
 	"github.com/oid-directory/go-radit/internal/jii"
 )
 
+/*
+RADIT contains an in-memory directory information tree, populated by way of
+the various Prime and Import methods.
+
+Instances of this type are safe for concurrent use. Priming and importing are
+serialized, while lookups and writes may proceed in parallel with one another.
+Note that writes which sort or spatially order the tree must modify it, and are
+therefore serialized as well.
+*/
 type RADIT struct {
 	dit *common.DIT
 }
@@ -33,6 +46,8 @@ instances which belong to the "itu-t" root.
 */
 func (r *RADIT) PrimeITUT() {
 	if !r.IsZero() {
+		r.dit.Lock()
+		defer r.dit.Unlock()
 		r.dit.Prime(0, itu.Tree...)
 	}
 }
@@ -43,6 +58,8 @@ instances which belong to the "iso" root.
 */
 func (r *RADIT) PrimeISO() {
 	if !r.IsZero() {
+		r.dit.Lock()
+		defer r.dit.Unlock()
 		r.dit.Prime(1, iso.Tree...)
 	}
 }
@@ -53,6 +70,8 @@ instances which belong to the "joint-iso-itu-t" root.
 */
 func (r *RADIT) PrimeJointISOITUT() {
 	if !r.IsZero() {
+		r.dit.Lock()
+		defer r.dit.Unlock()
 		r.dit.Prime(2, jii.Tree...)
 	}
 }
@@ -63,15 +82,58 @@ registries to be imported, or loaded, into the receiver instance.
 
 Valid key names are as follows, and must be case-folded as shown.
 
+  - "ldiffile" specifies the full path and filename of an LDIF file previously produced by [RADIT.Write] or [RADIT.WriteLDIF]
+  - "jsonfile" specifies the full path and filename of a JSON file previously produced by [RADIT.WriteJSON] or [RADIT.WriteJSONLines]
   - "smifile" specifies the full path and filename of IANA's SMI registry XML file
   - "ldapfile" specifies the full path and filename of IANA's LDAP registry XML file
   - "penfile" specifies the full path and filename of IANA's PEN numbers TXT file
+  - "csvfile" specifies the full path and filename of a CSV file of custom registrations
+
+The header row of a "csvfile" source MUST name its columns, which may include
+dotNotation (required), identifier, description, cn, o, email, status, range
+and uri. Multiple uri values are delimited by the '|' character. Each row is
+allocated beneath the appropriate root and, if registrant details are present,
+a registrant is attached according to the registrants policy in force.
+
+An "ldiffile" or "jsonfile" source is loaded first, such that a previous dump,
+perhaps edited by hand, may be augmented by any of the other sources. Registrant
+entries are only honored under the terms of the "Dedicated Registrants
+Policy", and subentries are merged into their respective registrations.
+
+Sources are imported in the order shown above.
 */
 type ImportList map[string]string
 
+/*
+ImportSources specifies the key name and [io.Reader] instance for each of
+the desired registries to be imported, or loaded, into the receiver instance.
+
+Valid key names are identical to those described for [ImportList].
+*/
+type ImportSources map[string]io.Reader
+
+/*
+importers defines the order in which sources are imported, alongside the
+function used to load each and that used to parse each in advance during a
+concurrent import.
+*/
+var importers = []struct {
+	key   string
+	funk  func(*common.DIT, io.Reader) error
+	parse func(io.Reader, common.ImportOptions) (common.Applier, error)
+}{
+	{`ldiffile`, common.LoadLDIFRegistry, common.ParseLDIFRegistry},
+	{`jsonfile`, common.LoadJSONRegistry, common.ParseJSONRegistry},
+	{`smifile`, iso.LoadSMIRegistry, iso.ParseSMIRegistry},
+	{`ldapfile`, iso.LoadSMIRegistry, iso.ParseSMIRegistry},
+	{`penfile`, iso.LoadPENRegistry, iso.ParsePENRegistry},
+	{`csvfile`, common.LoadCSVRegistry, common.ParseCSVRegistry},
+}
+
 /*
 Import returns an error following an attempt to load the contents specified
-within the input [ImportList] instance into the receiver instance.
+within the input [ImportList] instance into the receiver instance. Each path
+is opened from the local filesystem.
 */
 func (r *RADIT) Import(imp ImportList) (err error) {
 	if imp == nil || len(imp) == 0 {
@@ -79,20 +141,228 @@ func (r *RADIT) Import(imp ImportList) (err error) {
 		return
 	}
 
-	type index struct {
-		file string
-		funk func(*common.DIT, string) error
+	return r.importFiles(imp, func(name string) (io.ReadCloser, error) {
+		return os.Open(name)
+	})
+}
+
+/*
+ImportFS returns an error following an attempt to load the contents specified
+within the input [ImportList] instance into the receiver instance. Each path
+is opened from the input [fs.FS] instance, such as an [embed.FS], and must
+therefore satisfy [fs.ValidPath].
+*/
+func (r *RADIT) ImportFS(fsys fs.FS, imp ImportList) (err error) {
+	if fsys == nil {
+		err = errors.New("fs.FS instance is nil, aborting import")
+		return
+	} else if imp == nil || len(imp) == 0 {
+		err = errors.New("ImportList instance is nil, aborting import")
+		return
+	}
+
+	return r.importFiles(imp, func(name string) (io.ReadCloser, error) {
+		return fsys.Open(name)
+	})
+}
+
+/*
+ImportReaders returns an error following an attempt to load the contents
+read from each [io.Reader] within the input [ImportSources] instance into
+the receiver instance. The caller remains responsible for closing any of
+the readers, if applicable.
+*/
+func (r *RADIT) ImportReaders(src ImportSources) (err error) {
+	if src == nil || len(src) == 0 {
+		err = errors.New("ImportSources instance is nil, aborting import")
+		return
+	} else if r.IsZero() {
+		err = errors.New("RADIT instance is nil, aborting import")
+		return
+	} else if r.ImportOptions().Concurrent {
+		err = r.importConcurrent(src)
+		return
+	}
+
+	r.dit.Lock()
+	defer r.dit.Unlock()
+
+	for i := 0; i < len(importers) && err == nil; i++ {
+		if reader, specified := src[importers[i].key]; specified {
+			r.dit.Report().Begin(importers[i].key)
+			err = importers[i].funk(r.dit, reader)
+			r.dit.Commit(importers[i].key)
+		}
+	}
+
+	return
+}
+
+/*
+ImportOptions contains the settings which govern the behavior of the
+importers. The zero value reflects the default behavior. See
+[RADIT.SetImportOptions].
+*/
+type ImportOptions = common.ImportOptions
+
+/*
+SetImportOptions assigns the input [ImportOptions] to the receiver instance,
+affecting all subsequent imports.
+
+For example, the following retains obsolete SMI records, which are otherwise
+skipped, as registrations bearing the OBSOLETE status:
+
+	r.SetImportOptions(ImportOptions{KeepObsolete: true})
+*/
+func (r *RADIT) SetImportOptions(opts ImportOptions) {
+	if !r.IsZero() {
+		r.dit.Lock()
+		defer r.dit.Unlock()
+		r.dit.SetOptions(opts)
+	}
+}
+
+/*
+ImportOptions returns the [ImportOptions] in force for the receiver instance.
+*/
+func (r *RADIT) ImportOptions() (opts ImportOptions) {
+	if !r.IsZero() {
+		r.dit.RLock()
+		defer r.dit.RUnlock()
+		opts = r.dit.Options()
+	}
+
+	return
+}
+
+/*
+ImportReport describes the records which were skipped or altered during
+one or more imports. See [RADIT.Report].
+*/
+type ImportReport = common.ImportReport
+
+/*
+SkippedRecord describes a single record which was not imported, such as an
+obsolete record or one whose value could not be parsed.
+*/
+type SkippedRecord = common.SkippedRecord
+
+/*
+IdentifierRewrite describes a single identifier which was derived from
+something other than the verbatim name of a record.
+*/
+type IdentifierRewrite = common.IdentifierRewrite
+
+/*
+ImportWarning describes a single irregularity which was tolerated, such as
+a PEN Registry entry lacking an email line.
+*/
+type ImportWarning = common.ImportWarning
+
+/*
+SourceCounts contains the number of skipped records and identifier rewrites
+reported for a single source. See [ImportReport.Counts].
+*/
+type SourceCounts = common.SourceCounts
+
+/*
+Report returns the *[ImportReport] instance which describes all imports
+performed by the receiver instance thus far. Each entry is tagged with the
+key of its source, e.g.: "smifile".
+
+Records found within registries that bear no OID, such as those enumerating
+LDAP result codes, are not registrations and are therefore not reported.
+
+The return instance is a copy, taken once any import in progress completes,
+and is not updated by subsequent imports.
+*/
+func (r *RADIT) Report() (report *ImportReport) {
+	if !r.IsZero() {
+		r.dit.RLock()
+		defer r.dit.RUnlock()
+		report = r.dit.Report().Copy()
+	}
+
+	return
+}
+
+func (r *RADIT) importFiles(imp ImportList, open func(string) (io.ReadCloser, error)) (err error) {
+	if r.IsZero() {
+		err = errors.New("RADIT instance is nil, aborting import")
+		return
+	} else if r.ImportOptions().Concurrent {
+		src := make(ImportSources, len(imp))
+		for i := 0; i < len(importers) && err == nil; i++ {
+			if file, specified := imp[importers[i].key]; specified {
+				var rc io.ReadCloser
+				if rc, err = open(file); err == nil {
+					src[importers[i].key] = rc
+					defer rc.Close()
+				}
+			}
+		}
+
+		if err == nil {
+			err = r.importConcurrent(src)
+		}
+		return
+	}
+
+	r.dit.Lock()
+	defer r.dit.Unlock()
+
+	for i := 0; i < len(importers) && err == nil; i++ {
+		if file, specified := imp[importers[i].key]; specified {
+			var rc io.ReadCloser
+			if rc, err = open(file); err == nil {
+				r.dit.Report().Begin(importers[i].key)
+				err = importers[i].funk(r.dit, rc)
+				r.dit.Commit(importers[i].key)
+				rc.Close()
+			}
+		}
 	}
 
-	indices := []index{
-		{`smifile`, iso.LoadSMIRegistry},
-		{`ldapfile`, iso.LoadSMIRegistry},
-		{`penfile`, iso.LoadPENRegistry},
+	return
+}
+
+/*
+importConcurrent parses each source specified within the input [ImportSources]
+instance in its own goroutine. Once all have been parsed, the results are applied
+to the underlying DIT sequentially in the order defined by importers, such that
+the result does not differ from that of a sequential import.
+*/
+func (r *RADIT) importConcurrent(src ImportSources) (err error) {
+	opts := r.ImportOptions()
+	apply := make([]common.Applier, len(importers))
+	errs := make([]error, len(importers))
+
+	var wg sync.WaitGroup
+	for i := 0; i < len(importers); i++ {
+		if reader, specified := src[importers[i].key]; specified {
+			wg.Add(1)
+			go func(i int, reader io.Reader) {
+				defer wg.Done()
+				apply[i], errs[i] = importers[i].parse(reader, opts)
+			}(i, reader)
+		}
+	}
+	wg.Wait()
+
+	for i := 0; i < len(importers) && err == nil; i++ {
+		err = errs[i]
 	}
 
-	for i := 0; i < len(indices) && err == nil; i++ {
-		if file, specified := imp[indices[i].file]; specified {
-			err = indices[i].funk(r.dit, file)
+	// Only the application of parsed content
+	// requires exclusive access to the DIT.
+	r.dit.Lock()
+	defer r.dit.Unlock()
+
+	for i := 0; i < len(importers) && err == nil; i++ {
+		if apply[i] != nil {
+			r.dit.Report().Begin(importers[i].key)
+			err = apply[i](r.dit)
+			r.dit.Commit(importers[i].key)
 		}
 	}
 
@@ -107,38 +377,19 @@ Use of this method is pretty costly, but is intended for a "once-in-a-lifetime
 context" to seed a new directory tree with entries. Keep in mind that OIDs rarely
 change.
 
+Callers wishing to avoid holding the entire dump in memory should use the
+[RADIT.WriteLDIF] method instead, which this method wraps.
+
 See _examples/main.go for a demonstration of this method.
 */
 func (r *RADIT) Write(sortByNumberForm, spatialXY, subentries bool) (buf *bytes.Buffer) {
-
-	if sortByNumberForm {
-		// sort the ENTIRE root by number form magnitude
-		r.dit.ITUT().SortByNumberForm(sortByNumberForm)
-		r.dit.ISO().SortByNumberForm(sortByNumberForm)
-		r.dit.JointISOITUT().SortByNumberForm(sortByNumberForm)
-	}
-
-	if spatialXY {
-		// Order ALL registrations according
-		// to number form along X and Y axes.
-		r.dit.ITUT().SetXAxes(spatialXY)
-		r.dit.ITUT().SetYAxes(spatialXY)
-		r.dit.ISO().SetXAxes(spatialXY)
-		r.dit.ISO().SetYAxes(spatialXY)
-		r.dit.JointISOITUT().SetXAxes(spatialXY)
-		r.dit.JointISOITUT().SetYAxes(spatialXY)
-	}
-
-	// Finally, dump the content to the byte buffer
 	buf = new(bytes.Buffer)
-	buf.WriteString(r.dit.ITUT().LDIF(2, subentries))
-	buf.WriteString(r.dit.ISO().LDIF(2, subentries))
-	buf.WriteString(r.dit.JointISOITUT().LDIF(2, subentries))
-
-	if r.dit.Profile().Dedicated() {
-		// DEDICATED registrants are in use; include in buffer.
-		buf.WriteString(r.dit.Registrants().LDIF())
-	}
+	_, _ = r.WriteLDIF(buf, WriteOptions{
+		SortByNumberForm: sortByNumberForm,
+		SpatialXY:        spatialXY,
+		Subentries:       subentries,
+		Registrants:      true,
+	})
 
 	return
 }
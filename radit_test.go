@@ -1,13 +1,21 @@
 package radit
 
 import (
+	"bytes"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
-	_ "embed"
+	"embed"
 
 	"github.com/oid-directory/go-radir"
+	"github.com/oid-directory/go-radir/oid"
+	"github.com/oid-directory/go-radit/internal/common"
 )
 
 //go:embed testdata/iana.xml
@@ -19,6 +27,148 @@ var testLDAPXML []byte
 //go:embed testdata/pen.txt
 var testPENTXT []byte
 
+//go:embed testdata
+var testFS embed.FS
+
+/*
+wantLDIFLen returns the exact byte length of a complete LDIF dump of the test
+registries by the input instance.
+
+747777 bytes were produced before the PEN Registry header was parsed and
+recorded, which also recovered enterprise numbers 0 and 1 (previously dropped
+by skipping a fixed number of lines). The remainder consists of the entries of
+those two enterprises and their registrants, and of the header values recorded
+upon their superior; all other content must be unchanged.
+*/
+func wantLDIFLen(t *testing.T, dit *RADIT) (n int) {
+	t.Helper()
+
+	n = 747777
+	for _, dot := range []string{`1.3.6.1.4.1.0`, `1.3.6.1.4.1.1`} {
+		reg, err := dit.Lookup(dot)
+		if err != nil {
+			t.Fatalf("%s failed: %v", t.Name(), err)
+		}
+
+		n += len(reg.LDIF(0, true))
+		for _, dn := range reg.X660().CurrentAuthorities() {
+			n += len(dit.dit.Registrants().Get(dn).LDIF())
+		}
+	}
+
+	parent, err := dit.Lookup(`1.3.6.1.4.1`)
+	if err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	entry := parent.LDIF(0, false)
+	for _, val := range []string{
+		`PEN Registry last updated 2026-06-23`,
+		`https://www.iana.org/assignments/enterprise-numbers.txt PEN Registry`,
+	} {
+		k := ldifLineLen(entry, val)
+		if k == 0 {
+			t.Fatalf("%s failed: enterprise entry lacks %q:\n%s", t.Name(), val, entry)
+		}
+		n += k
+	}
+
+	return
+}
+
+/*
+ldifLineLen returns the byte length, folding and newlines included, of the
+lines of the input LDIF content bearing the input value.
+*/
+func ldifLineLen(content, val string) (n int) {
+	lines := strings.SplitAfter(content, "\n")
+	for i := 0; i < len(lines); {
+		logical, size := strings.TrimSuffix(lines[i], "\n"), len(lines[i])
+		for i++; i < len(lines) && strings.HasPrefix(lines[i], ` `); i++ {
+			logical += strings.TrimSuffix(lines[i][1:], "\n")
+			size += len(lines[i])
+		}
+
+		if strings.HasSuffix(logical, `: `+val) {
+			n += size
+		}
+	}
+
+	return
+}
+
+/*
+checkLDIF fails t unless the input LDIF content, as produced from the full
+set of test registries by the input instance, is complete. The content must
+match an independent depth-first rendering of the instance, and bear every
+enterprise number of the test PEN registry exactly once.
+*/
+func checkLDIF(t *testing.T, dit *RADIT, content string) {
+	t.Helper()
+
+	var want strings.Builder
+	var render func(*radir.Registration)
+	render = func(reg *radir.Registration) {
+		want.WriteString(reg.LDIF(0, true))
+		kids := reg.Children()
+		for i := 0; i < kids.Len(); i++ {
+			render(kids.Index(i))
+		}
+	}
+	for n := 0; n < 3; n++ {
+		render(dit.dit.Root(n))
+	}
+	if aths := dit.dit.Registrants(); dit.dit.Profile().Dedicated() {
+		for i := 0; i < aths.Len(); i++ {
+			want.WriteString(aths.Index(i).LDIF())
+		}
+	}
+
+	if want.Len() != len(content) || want.String() != content {
+		t.Fatalf("%s failed: unexpected content; want %d bytes, got %d",
+			t.Name(), want.Len(), len(content))
+	}
+
+	// Unfold continued lines before comparing DNs.
+	dns := make(map[string]bool)
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\n ", ""), "\n") {
+		if strings.HasPrefix(line, `dn: `) {
+			if dns[line] {
+				t.Fatalf("%s failed: duplicate entry %q", t.Name(), line)
+			}
+			dns[line] = true
+		}
+	}
+
+	// Every enterprise number in testdata/pen.txt, 0 and 1 included,
+	// must be present.
+	var pens int
+	for _, line := range strings.Split(string(testPENTXT), "\n") {
+		line = strings.TrimSpace(line)
+		if _, err := strconv.ParseUint(line, 10, 64); err != nil {
+			continue
+		}
+		pens++
+		if _, err := dit.Lookup(`1.3.6.1.4.1.` + line); err != nil {
+			t.Fatalf("%s failed: enterprise %s: %v", t.Name(), line, err)
+		}
+	}
+	if pens != 101 {
+		t.Fatalf("%s failed: unexpected enterprise count; want 101, got %d",
+			t.Name(), pens)
+	}
+
+	for _, want := range []string{
+		`PEN Registry last updated 2026-06-23`,
+		`NxNetworks`,
+		`iana@iana.org`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Fatalf("%s failed: content lacks %q", t.Name(), want)
+		}
+	}
+}
+
 func TestDITLoad(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -67,10 +217,10 @@ func TestDITLoad(t *testing.T) {
 
 	content := dit.Write(true, true, true)
 
-	want := 747777
-	if got := content.Len(); want != got {
+	if want, got := wantLDIFLen(t, dit), content.Len(); want != got {
 		t.Fatalf("%s failed: unexpected byte len; want %d, got %d", t.Name(), want, got)
 	}
+	checkLDIF(t, dit, content.String())
 
 	t.Logf("%s\n", content.String())
 
@@ -93,4 +243,1404 @@ func TestDITLoad_codecov(_ *testing.T) {
 	dit := New(cfg.Profile())
 	_ = dit.Import(nil)
 	_ = dit.Import(ImportList{})
+	_ = dit.ImportReaders(nil)
+	_ = dit.ImportFS(nil, ImportList{})
+	_ = dit.ImportFS(testFS, ImportList{`penfile`: `testdata/missing.txt`})
+
+	var nilDIT *RADIT
+	_, _ = nilDIT.WriteLDIF(nil, WriteOptions{})
+	_, _ = dit.WriteLDIF(nil, WriteOptions{})
+}
+
+func TestWriteLDIF(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+
+	dit.PrimeITUT()
+	dit.PrimeISO()
+	dit.PrimeJointISOITUT()
+
+	if err := dit.ImportReaders(ImportSources{
+		`smifile`:  bytes.NewReader(testSMIXML),
+		`ldapfile`: bytes.NewReader(testLDAPXML),
+		`penfile`:  bytes.NewReader(testPENTXT),
+	}); err != nil {
+		t.Fatalf("%s failed: unable to import one or more sources: %v", t.Name(), err)
+	}
+
+	var buf bytes.Buffer
+	n, err := dit.WriteLDIF(&buf, WriteOptions{
+		SortByNumberForm: true,
+		SpatialXY:        true,
+		Subentries:       true,
+		Registrants:      true,
+	})
+	if err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	if want, got := wantLDIFLen(t, dit), buf.Len(); want != got || int64(got) != n {
+		t.Fatalf("%s failed: unexpected byte len; want %d, got %d (reported %d)",
+			t.Name(), want, got, n)
+	}
+	checkLDIF(t, dit, buf.String())
+
+	report := dit.Report()
+	counts := report.Counts()
+	if len(report.Skipped) == 0 || counts[`smifile`].Skipped == 0 {
+		t.Fatalf("%s failed: expected skipped SMI records in report, got %#v", t.Name(), counts)
+	} else if len(report.Rewrites) == 0 {
+		t.Fatalf("%s failed: expected identifier rewrites in report", t.Name())
+	}
+
+	for _, skip := range report.Skipped {
+		if skip.Source == "" || skip.Registry == "" || skip.Reason == "" {
+			t.Fatalf("%s failed: incomplete skipped record: %#v", t.Name(), skip)
+		}
+	}
+
+	// A rewrite of a missing name must record the origin of its
+	// replacement first, whatever was done to it thereafter.
+	for _, rw := range report.Rewrites {
+		if len(rw.Methods) == 0 {
+			t.Fatalf("%s failed: rewrite lacks methods: %#v", t.Name(), rw)
+		} else if rw.Original == "" && rw.Methods[0] == common.RewriteLegalized {
+			t.Fatalf("%s failed: rewrite provenance overwritten: %#v", t.Name(), rw)
+		}
+	}
+}
+
+func TestWriteLDIF_bases(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+	dit.PrimeISO()
+
+	var whole, sub bytes.Buffer
+	if _, err := dit.WriteLDIF(&whole, WriteOptions{Bases: []string{`iso`}}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	if _, err := dit.WriteLDIF(&sub, WriteOptions{
+		Bases:    []string{`1.3.6.1`, `1`},
+		MaxDepth: 1,
+	}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	if sub.Len() == 0 || sub.Len() >= whole.Len() {
+		t.Fatalf("%s failed: unexpected byte len; want (0, %d), got %d",
+			t.Name(), whole.Len(), sub.Len())
+	}
+
+	if _, err := dit.WriteLDIF(&sub, WriteOptions{Bases: []string{`1.9999`}}); err == nil {
+		t.Fatalf("%s failed: expected error for unknown base, got nil", t.Name())
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+
+	dit.PrimeISO()
+	dit.PrimeJointISOITUT()
+
+	if err := dit.Import(ImportList{`csvfile`: `testdata/custom.csv`}); err != nil {
+		t.Fatalf("%s failed: unable to import CSV file: %v", t.Name(), err)
+	}
+
+	for dot, id := range map[string]string{
+		`1.3.6.1.4.1.56521.999`:   `testArc`,
+		`1.3.6.1.4.1.56521.999.1`: `testLeaf`,
+		`2.999.56521`:             ``,
+	} {
+		reg := dit.dit.Resolve(dot)
+		if reg.IsZero() {
+			t.Fatalf("%s failed: %s not allocated", t.Name(), dot)
+		} else if got := reg.X680().Identifier(); id != "" && got != id {
+			t.Fatalf("%s failed: unexpected identifier for %s; want %s, got %s",
+				t.Name(), dot, id, got)
+		}
+	}
+
+	if got := dit.dit.Resolve(`1.3.6.1.4.1.56521.999.1`).Supplement().Status(); got != `OBSOLETE` {
+		t.Fatalf("%s failed: unexpected status; want OBSOLETE, got %s", t.Name(), got)
+	}
+}
+
+func TestImportCSV_idempotent(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+	dit.PrimeISO()
+	dit.PrimeJointISOITUT()
+
+	// Rows bear no registrant DN, thus registrants must be
+	// matched by value lest each import create them anew.
+	var want int
+	for i := 0; i < 2; i++ {
+		if err := dit.ImportFS(testFS, ImportList{`csvfile`: `testdata/custom.csv`}); err != nil {
+			t.Fatalf("%s failed: %v", t.Name(), err)
+		} else if i == 0 {
+			want = dit.dit.Registrants().Len()
+		}
+	}
+
+	if got := dit.dit.Registrants().Len(); got != want || want != 1 {
+		t.Fatalf("%s failed: want 1 registrant, got %d (first import: %d)", t.Name(), got, want)
+	}
+
+	reg, err := dit.Lookup(`1.3.6.1.4.1.56521.999`)
+	if err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if cas := reg.X660().CurrentAuthorities(); len(cas) != 1 {
+		t.Fatalf("%s failed: want 1 registrant reference, got %v", t.Name(), cas)
+	} else if uris := reg.Supplement().URI(); len(uris) != 1 {
+		t.Fatalf("%s failed: duplicate URIs following re-import: %v", t.Name(), uris)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+	dit.PrimeISO()
+
+	const want = `1.3.6.1.4.1`
+	for _, funk := range []func() (*radir.Registration, error){
+		func() (*radir.Registration, error) { return dit.Lookup(want) },
+		func() (*radir.Registration, error) {
+			return dit.LookupASN1(`{iso(1) identified-organization(3) dod(6) internet(1) private(4) enterprise(1)}`)
+		},
+		func() (*radir.Registration, error) {
+			return dit.LookupASN1(`{iso identified-organization dod internet private 1}`)
+		},
+		func() (*radir.Registration, error) { return dit.LookupIRI(`/ISO/3/6/1/4/1`) },
+		func() (*radir.Registration, error) {
+			return dit.LookupIdentifiers(`iso`, `identified-organization`, `dod`, `internet`, `private`, `1`)
+		},
+	} {
+		reg, err := funk()
+		if err != nil {
+			t.Fatalf("%s failed: %v", t.Name(), err)
+		} else if got := reg.X680().DotNotation(); got != want {
+			t.Fatalf("%s failed: want %s, got %s", t.Name(), want, got)
+		}
+	}
+
+	if _, err := dit.Lookup(`1.3.6.1.4.1.99999999999`); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("%s failed: want ErrNotFound, got %v", t.Name(), err)
+	} else if _, err = dit.LookupASN1(`{iso(1) bogus-arc}`); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("%s failed: want ErrNotFound, got %v", t.Name(), err)
+	} else if _, err = dit.Lookup(`not.an.oid`); err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("%s failed: want syntax error, got %v", t.Name(), err)
+	}
+}
+
+func TestFindByName(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+	dit.PrimeISO()
+
+	if regs := dit.FindByName(`ifType`, MatchExact); len(regs) != 1 {
+		t.Fatalf("%s failed: want 1 exact match, got %d", t.Name(), len(regs))
+	} else if got := regs[0].X680().DotNotation(); got != `1.3.6.1.2.1.2.2.1.3` {
+		t.Fatalf("%s failed: unexpected match %s", t.Name(), got)
+	}
+
+	if regs := dit.FindByName(`IFTYPE`, MatchExact); len(regs) != 0 {
+		t.Fatalf("%s failed: want no exact match, got %d", t.Name(), len(regs))
+	} else if regs = dit.FindByName(`IFTYPE`, MatchFold); len(regs) != 1 {
+		t.Fatalf("%s failed: want 1 folded match, got %d", t.Name(), len(regs))
+	}
+
+	if regs := dit.FindByName(`mpls`, MatchPrefix); len(regs) < 2 {
+		t.Fatalf("%s failed: want several prefix matches, got %d", t.Name(), len(regs))
+	} else if folded := dit.FindByName(`MPLS`, MatchPrefix|MatchFold); len(folded) < len(regs) {
+		t.Fatalf("%s failed: folded prefix matches (%d) fewer than prefix matches (%d)",
+			t.Name(), len(folded), len(regs))
+	}
+
+	if err := dit.ImportReaders(ImportSources{
+		`penfile`: bytes.NewReader(testPENTXT),
+	}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if regs := dit.FindByName(`enterprise`, MatchExact); len(regs) == 0 {
+		t.Fatalf("%s failed: index not updated following import", t.Name())
+	}
+}
+
+func TestSearch(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+	dit.PrimeISO()
+
+	if err := dit.ImportReaders(ImportSources{
+		`penfile`: bytes.NewReader(testPENTXT),
+	}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	results, err := dit.Search(`michael KELLEN`, 5, `1.3.6.1.4.1`)
+	if err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if len(results) == 0 {
+		t.Fatalf("%s failed: no results", t.Name())
+	} else if got := results[0].Registration.X680().DotNotation(); got != `1.3.6.1.4.1.1` {
+		t.Fatalf("%s failed: unexpected top result %s", t.Name(), got)
+	}
+
+	if results, err = dit.Search(`NxNetworks`, 0, `itu-t`); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if len(results) != 0 {
+		t.Fatalf("%s failed: want no results beneath itu-t, got %d", t.Name(), len(results))
+	}
+
+	if _, err = dit.Search(`NxNetworks`, 0, `1.3.6.1.99999`); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("%s failed: want ErrNotFound, got %v", t.Name(), err)
+	}
+}
+
+func TestImportPEN_reimport(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+	dit.PrimeISO()
+
+	// The header values recorded upon the enterprise
+	// arc must not accumulate across imports.
+	for i := 0; i < 2; i++ {
+		if err := dit.ImportReaders(ImportSources{
+			`penfile`: bytes.NewReader(testPENTXT),
+		}); err != nil {
+			t.Fatalf("%s failed: %v", t.Name(), err)
+		}
+	}
+
+	reg, err := dit.Lookup(`1.3.6.1.4.1`)
+	if err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	for _, strukt := range []struct {
+		Vals []string
+		Want string
+	}{
+		{reg.Supplement().Info(), `PEN Registry last updated 2026-06-23`},
+		{reg.Supplement().URI(), `https://www.iana.org/assignments/enterprise-numbers.txt PEN Registry`},
+	} {
+		var cnt int
+		for _, val := range strukt.Vals {
+			if val == strukt.Want {
+				cnt++
+			}
+		}
+
+		if cnt != 1 {
+			t.Fatalf("%s failed: want %q once, got %v", t.Name(), strukt.Want, strukt.Vals)
+		}
+	}
+}
+
+func TestImport_incremental(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+	dit.PrimeISO()
+	dit.PrimeJointISOITUT()
+
+	if err := dit.ImportReaders(ImportSources{
+		`penfile`: bytes.NewReader(testPENTXT),
+	}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	report := dit.Report()
+	report.Skipped = append(report.Skipped, common.SkippedRecord{Reason: `test`})
+
+	if err := dit.ImportFS(testFS, ImportList{`csvfile`: `testdata/custom.csv`}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if got := len(dit.Report().Skipped); got != len(report.Skipped)-1 {
+		t.Fatalf("%s failed: report copy shares state with the instance", t.Name())
+	}
+
+	// Only the registrations touched by the CSV import are indexed
+	// anew, alongside the superiors allocated on their behalf.
+	if regs := dit.FindByName(`testLeaf`, MatchExact); len(regs) != 1 {
+		t.Fatalf("%s failed: want 1 match, got %d", t.Name(), len(regs))
+	} else if regs = dit.FindByName(`enterprise`, MatchExact); len(regs) == 0 {
+		t.Fatalf("%s failed: prior index entries lost", t.Name())
+	}
+
+	for query, want := range map[string]string{
+		`joint arc`:  `2.999.56521`,
+		`NxNetworks`: ``,
+	} {
+		results, err := dit.Search(query, 1)
+		if err != nil || len(results) != 1 {
+			t.Fatalf("%s failed: %q: want 1 result, got %d (%v)",
+				t.Name(), query, len(results), err)
+		} else if got := results[0].Registration.X680().DotNotation(); want != "" && got != want {
+			t.Fatalf("%s failed: %q: want %s, got %s", t.Name(), query, want, got)
+		}
+	}
+}
+
+func TestImportLDIF_roundTrip(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+	dit.PrimeITUT()
+	dit.PrimeISO()
+	dit.PrimeJointISOITUT()
+
+	if err := dit.ImportFS(testFS, ImportList{
+		`smifile`:  `testdata/iana.xml`,
+		`ldapfile`: `testdata/ldap.xml`,
+		`penfile`:  `testdata/pen.txt`,
+	}); err != nil {
+		t.Fatalf("%s failed: unable to import: %v", t.Name(), err)
+	}
+	want := dit.Write(true, true, true).String()
+
+	// Registrations allocated by the LDAP registry must survive.
+	for _, dot := range []string{`1.3.6.1.4.1.1466`, `1.3.6.1.4.1.4203`} {
+		if _, err := dit.Lookup(dot); err != nil {
+			t.Fatalf("%s failed: %s: %v", t.Name(), dot, err)
+		}
+	}
+
+	reload := New(cfg.Profile())
+	if err := reload.ImportReaders(ImportSources{
+		`ldiffile`: strings.NewReader(want),
+	}); err != nil {
+		t.Fatalf("%s failed: unable to import LDIF: %v", t.Name(), err)
+	}
+
+	if got := reload.Write(true, true, true).String(); got != want {
+		t.Fatalf("%s failed: round trip output differs (%d != %d bytes)",
+			t.Name(), len(got), len(want))
+	}
+}
+
+func TestImportLDIF_escapedDN(t *testing.T) {
+	// Neither RDN splitting nor superior lookup may
+	// be fooled by a comma escaped per RFC 4514.
+	const content = `dn: n=3,n=1,ou=Registrations,o=rA
+objectClass: registration
+n: 3
+
+dn: cn=Smith\, John,n=3,n=1,ou=Registrations,o=rA
+objectClass: registrationSupplement
+cn: Smith, John
+`
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+	if err := dit.ImportReaders(ImportSources{
+		`ldiffile`: strings.NewReader(content),
+	}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if _, err = dit.Lookup(`1.3`); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+}
+
+func TestParseLDIF(t *testing.T) {
+	const content = `version: 1
+
+# a comment which
+  continues here
+dn: n=1,ou=Registrations,o=rA
+objectClass: registration
+description: a fol
+ ded description
+dn:: bj0yLG91PVJlZ2lzdHJhdGlvbnMsbz1yQQ==
+
+`
+	if _, err := common.ParseLDIF(strings.NewReader(content)); err == nil {
+		t.Fatalf("%s failed: expected error for multiple dn lines", t.Name())
+	}
+
+	entries, err := common.ParseLDIF(strings.NewReader(strings.Replace(content,
+		"dn:: ", "\ndn:: ", 1)))
+	if err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if len(entries) != 2 {
+		t.Fatalf("%s failed: want 2 entries, got %d", t.Name(), len(entries))
+	} else if got := entries[0].Value(`description`); got != `a folded description` {
+		t.Fatalf("%s failed: unexpected unfolded value '%s'", t.Name(), got)
+	} else if got = entries[1].DN; got != `n=2,ou=Registrations,o=rA` {
+		t.Fatalf("%s failed: unexpected decoded DN '%s'", t.Name(), got)
+	}
+}
+
+func TestJSON_roundTrip(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+	dit.PrimeISO()
+	if err := dit.ImportReaders(ImportSources{
+		`penfile`: bytes.NewReader(testPENTXT),
+		`csvfile`: strings.NewReader("dotNotation,identifier,status\n1.3.6.1.4.1.56521.999,testArc,obsolete\n"),
+	}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	opts := WriteOptions{SortByNumberForm: true, Registrants: true}
+	for name, funk := range map[string]func(*RADIT, io.Writer, WriteOptions) (int64, error){
+		`nested`: (*RADIT).WriteJSON,
+		`lines`:  (*RADIT).WriteJSONLines,
+	} {
+		var want bytes.Buffer
+		if n, err := funk(dit, &want, opts); err != nil {
+			t.Fatalf("%s failed (%s): %v", t.Name(), name, err)
+		} else if n != int64(want.Len()) {
+			t.Fatalf("%s failed (%s): byte count mismatch; want %d, got %d",
+				t.Name(), name, want.Len(), n)
+		}
+
+		reload := New(cfg.Profile())
+		if err := reload.ImportReaders(ImportSources{
+			`jsonfile`: bytes.NewReader(want.Bytes()),
+		}); err != nil {
+			t.Fatalf("%s failed (%s): unable to import JSON: %v", t.Name(), name, err)
+		}
+
+		var got bytes.Buffer
+		if _, err := funk(reload, &got, opts); err != nil {
+			t.Fatalf("%s failed (%s): %v", t.Name(), name, err)
+		} else if got.String() != want.String() {
+			t.Fatalf("%s failed (%s): round trip output differs (%d != %d bytes)",
+				t.Name(), name, got.Len(), want.Len())
+		}
+	}
+
+	if err := New(cfg.Profile()).ImportReaders(ImportSources{
+		`jsonfile`: strings.NewReader(`{"dotNotation":"1.3.6","bogus":true}`),
+	}); err == nil {
+		t.Fatalf("%s failed: expected error for unknown field", t.Name())
+	}
+}
+
+func TestJSON_registrants(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+
+	// A second import must neither create registrants
+	// anew nor duplicate any multi-valued attribute.
+	for i := 0; i < 2; i++ {
+		if err := dit.ImportFS(testFS, ImportList{`jsonfile`: `testdata/registrants.jsonl`}); err != nil {
+			t.Fatalf("%s failed: %v", t.Name(), err)
+		}
+	}
+
+	if got := dit.dit.Registrants().Len(); got != 2 {
+		t.Fatalf("%s failed: want 2 registrants, got %d", t.Name(), got)
+	}
+
+	reg, err := dit.Lookup(`1.3.6.1.4.1.56521.900`)
+	if err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if uris, info := reg.Supplement().URI(), reg.Supplement().Info(); len(uris) != 1 || len(info) != 1 {
+		t.Fatalf("%s failed: duplicate values following re-import: %v %v", t.Name(), uris, info)
+	} else if cas := reg.X660().CurrentAuthorities(); len(cas) != 1 {
+		t.Fatalf("%s failed: want 1 registrant reference, got %v", t.Name(), cas)
+	}
+
+	opts := WriteOptions{SortByNumberForm: true, Registrants: true}
+	var want bytes.Buffer
+	if _, err = dit.WriteJSONLines(&want, opts); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	for _, val := range []string{
+		`"dn":"cn=Example Registrant,ou=Registrants,o=rA"`,
+		`"description":"Shared registrant"`,
+		`"description":"Described registrant"`,
+	} {
+		if !strings.Contains(want.String(), val) {
+			t.Fatalf("%s failed: output lacks %s", t.Name(), val)
+		}
+	}
+
+	reload := New(cfg.Profile())
+	var got bytes.Buffer
+	if err = reload.ImportReaders(ImportSources{`jsonfile`: bytes.NewReader(want.Bytes())}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if _, err = reload.WriteJSONLines(&got, opts); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if got.String() != want.String() {
+		t.Fatalf("%s failed: round trip output differs:\n%s\n%s", t.Name(), want.String(), got.String())
+	} else if cnt := reload.dit.Registrants().Len(); cnt != 2 {
+		t.Fatalf("%s failed: want 2 registrants following reload, got %d", t.Name(), cnt)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+	dit.PrimeISO()
+	if err := dit.ImportReaders(ImportSources{
+		`penfile`: bytes.NewReader(testPENTXT),
+	}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	var buf bytes.Buffer
+	n, err := dit.WriteCSV(&buf, CSVOptions{
+		WriteOptions: WriteOptions{Bases: []string{`1.3.6.1.4.1`}, MaxDepth: 1},
+		Columns:      []string{`dotNotation`, `O`, `source`},
+		Comma:        '\t',
+	})
+	if err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if n != int64(buf.Len()) {
+		t.Fatalf("%s failed: byte count mismatch; want %d, got %d", t.Name(), buf.Len(), n)
+	}
+
+	rows := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if rows[0] != "dotNotation\to\tsource" {
+		t.Fatalf("%s failed: unexpected header '%s'", t.Name(), rows[0])
+	} else if !strings.HasPrefix(rows[1], "1.3.6.1.4.1\t") || !strings.HasSuffix(rows[1], "\tseed") {
+		t.Fatalf("%s failed: unexpected base row '%s'", t.Name(), rows[1])
+	} else if rows[3] != "1.3.6.1.4.1.1\tNxNetworks\tpenfile" {
+		t.Fatalf("%s failed: unexpected row '%s'", t.Name(), rows[3])
+	}
+
+	if _, err = dit.WriteCSV(io.Discard, CSVOptions{Columns: []string{`bogus`}}); err == nil {
+		t.Fatalf("%s failed: expected error for unknown column", t.Name())
+	}
+
+	// The default columns must be importable.
+	buf.Reset()
+	if _, err = dit.WriteCSV(&buf, CSVOptions{}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if err = New(cfg.Profile()).ImportReaders(ImportSources{
+		`csvfile`: &buf,
+	}); err != nil {
+		t.Fatalf("%s failed: unable to import CSV output: %v", t.Name(), err)
+	}
+}
+
+func TestWriteCSV_registrants(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+	if err := dit.ImportFS(testFS, ImportList{`jsonfile`: `testdata/registrants.jsonl`}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	opts := CSVOptions{
+		WriteOptions: WriteOptions{Bases: []string{
+			`1.3.6.1.4.1.56521.900`,
+			`1.3.6.1.4.1.56521.901`,
+		}},
+		Columns: []string{`dotNotation`, `o`, `cn`, `email`, `uri`, `info`},
+	}
+
+	var want bytes.Buffer
+	if _, err := dit.WriteCSV(&want, opts); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if !strings.Contains(want.String(),
+		"Example Org|,Example Registrant|Second Registrant,registrant@example.com|second@example.com") {
+		t.Fatalf("%s failed: unexpected registrant columns:\n%s", t.Name(), want.String())
+	}
+
+	// Multiple registrants, and info values, must survive re-import.
+	reload := New(cfg.Profile())
+	var got bytes.Buffer
+	if err := reload.ImportReaders(ImportSources{`csvfile`: bytes.NewReader(want.Bytes())}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if _, err = reload.WriteCSV(&got, opts); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if got.String() != want.String() {
+		t.Fatalf("%s failed: round trip output differs:\n%s\n%s", t.Name(), want.String(), got.String())
+	}
+
+	if reg, err := reload.Lookup(`1.3.6.1.4.1.56521.901`); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if cas := reg.X660().CurrentAuthorities(); len(cas) != 2 {
+		t.Fatalf("%s failed: want 2 registrant references, got %v", t.Name(), cas)
+	} else if cnt := reload.dit.Registrants().Len(); cnt != 2 {
+		t.Fatalf("%s failed: want 2 registrants following reload, got %d", t.Name(), cnt)
+	}
+}
+
+func TestWriteASN1(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+	dit.PrimeISO()
+	if err := dit.ImportReaders(ImportSources{
+		`smifile`: bytes.NewReader(testSMIXML),
+	}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := dit.WriteASN1(&buf, ASN1Options{
+		WriteOptions: WriteOptions{Bases: []string{`1.3.6.1`}},
+		Module:       `Test-Module`,
+	}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	out := buf.String()
+	if !strings.HasPrefix(out, "Test-Module DEFINITIONS ::=\nBEGIN\n") ||
+		!strings.HasSuffix(out, "\nEND\n") {
+		t.Fatalf("%s failed: malformed module", t.Name())
+	} else if !strings.Contains(out, " OBJECT IDENTIFIER ::= { iso(1) 3 6 1 } -- 1.3.6.1\n") {
+		t.Fatalf("%s failed: base not expressed relative to its root", t.Name())
+	}
+
+	names := make(map[string]bool)
+	for _, line := range strings.Split(out, "\n") {
+		if name, _, found := strings.Cut(line, " OBJECT IDENTIFIER ::= "); found {
+			if names[name] {
+				t.Fatalf("%s failed: duplicate value reference '%s'", t.Name(), name)
+			} else if !oid.IsNameForm(name) {
+				t.Fatalf("%s failed: illegal value reference '%s'", t.Name(), name)
+			}
+			names[name] = true
+		}
+	}
+
+	if _, err := dit.WriteASN1(io.Discard, ASN1Options{Module: `lowercase`}); err == nil {
+		t.Fatalf("%s failed: expected error for illegal module reference", t.Name())
+	}
+
+	// Once all candidates are taken, a numeric suffix is appended.
+	reg, err := dit.Lookup(`1.3.6.1`)
+	if err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	aw := &asn1Writer{names: map[string]bool{
+		`internet`:         true,
+		`internet-1`:       true,
+		`internet-1-3-6-1`: true,
+	}}
+	for _, want := range []string{`internet-1-3-6-1-2`, `internet-1-3-6-1-3`} {
+		if got := aw.name(reg, ``); got != want {
+			t.Fatalf("%s failed: want value reference '%s', got '%s'", t.Name(), want, got)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	old := New(cfg.Profile())
+	old.PrimeISO()
+
+	cur := New(cfg.Profile())
+	cur.PrimeISO()
+	if err := cur.ImportReaders(ImportSources{
+		`penfile`: bytes.NewReader(testPENTXT),
+	}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	var buf bytes.Buffer
+	if n, err := Diff(&buf, old, old); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if n != 0 || buf.Len() != 0 {
+		t.Fatalf("%s failed: want empty diff of identical trees, got %d bytes",
+			t.Name(), buf.Len())
+	}
+
+	n, err := Diff(&buf, old, cur)
+	if err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if n != int64(buf.Len()) {
+		t.Fatalf("%s failed: byte count mismatch; want %d, got %d", t.Name(), buf.Len(), n)
+	}
+	diff := buf.String()
+	if !strings.Contains(diff, "changetype: add\n") {
+		t.Fatalf("%s failed: no add records found", t.Name())
+	} else if strings.Contains(diff, "changetype: delete\n") {
+		t.Fatalf("%s failed: unexpected delete records found", t.Name())
+	}
+
+	buf.Reset()
+	if _, err = Diff(&buf, cur, old); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if !strings.Contains(buf.String(), "changetype: delete\n") {
+		t.Fatalf("%s failed: no delete records found", t.Name())
+	}
+
+	if _, err = Diff(&buf, nil, cur); err == nil {
+		t.Fatalf("%s failed: expected error for nil instance", t.Name())
+	} else if _, err = Diff(nil, old, cur); err == nil {
+		t.Fatalf("%s failed: expected error for nil writer", t.Name())
+	}
+}
+
+func TestDiff_rebuild(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+
+	// Two builds of the same source must not differ, whatever
+	// DNs were generated for their registrants.
+	dits := make([]*RADIT, 2)
+	for i := range dits {
+		dits[i] = New(cfg.Profile())
+		dits[i].PrimeISO()
+		if err := dits[i].ImportReaders(ImportSources{
+			`penfile`: bytes.NewReader(testPENTXT),
+		}); err != nil {
+			t.Fatalf("%s failed: %v", t.Name(), err)
+		}
+	}
+
+	if dits[0].dit.Registrants().Len() == 0 {
+		t.Fatalf("%s failed: no registrants imported", t.Name())
+	}
+
+	var buf bytes.Buffer
+	if n, err := Diff(&buf, dits[0], dits[1]); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if n != 0 || buf.Len() != 0 {
+		t.Fatalf("%s failed: want empty diff of identical builds, got %d bytes:\n%s",
+			t.Name(), buf.Len(), buf.String())
+	}
+}
+
+func TestDiff_moddnModify(t *testing.T) {
+	// Arc 1.999 moves to a new superior, taking its children with it.
+	// Beneath it, one child is added and another removed, while the
+	// description of arc 1.998 changes.
+	const before = `dn: n=998,n=1,ou=Registrations,o=rA
+n: 998
+dotNotation: 1.998
+description: before
+
+dn: n=999,n=1,ou=Registrations,o=rA
+n: 999
+dotNotation: 1.999
+description: moved arc
+
+dn: n=5,n=999,n=1,ou=Registrations,o=rA
+n: 5
+dotNotation: 1.999.5
+description: kept child
+
+dn: n=7,n=999,n=1,ou=Registrations,o=rA
+n: 7
+dotNotation: 1.999.7
+description: removed child
+`
+	const after = `dn: n=998,n=1,ou=Registrations,o=rA
+n: 998
+dotNotation: 1.998
+description: after
+
+dn: n=999,ou=Moved,o=rA
+n: 999
+dotNotation: 1.999
+description: moved arc
+
+dn: n=5,n=999,ou=Moved,o=rA
+n: 5
+dotNotation: 1.999.5
+description: kept child
+
+dn: n=6,n=999,ou=Moved,o=rA
+n: 6
+dotNotation: 1.999.6
+description: added child
+`
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dits := make([]*RADIT, 2)
+	for i, content := range []string{before, after} {
+		dits[i] = New(cfg.Profile())
+		if err := dits[i].ImportReaders(ImportSources{
+			`ldiffile`: strings.NewReader(content),
+		}); err != nil {
+			t.Fatalf("%s failed: %v", t.Name(), err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := Diff(&buf, dits[0], dits[1]); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+	diff := buf.String()
+
+	moddn := "dn: n=999,n=1,ou=Registrations,o=rA\nchangetype: moddn\n" +
+		"newrdn: n=999\ndeleteoldrdn: 1\nnewsuperior: ou=Moved,o=rA\n"
+	add := "dn: n=6,n=999,ou=Moved,o=rA\nchangetype: add\n"
+	modify := "dn: n=998,n=1,ou=Registrations,o=rA\nchangetype: modify\nreplace: description\n"
+	del := "dn: n=7,n=999,ou=Moved,o=rA\nchangetype: delete\n"
+
+	var last int
+	for _, want := range []string{moddn, add, modify, del} {
+		idx := strings.Index(diff, want)
+		if idx == -1 {
+			t.Fatalf("%s failed: diff lacks %q:\n%s", t.Name(), want, diff)
+		} else if idx < last {
+			t.Fatalf("%s failed: %q out of order:\n%s", t.Name(), want, diff)
+		}
+		last = idx
+	}
+
+	// The kept child is renamed alongside its superior.
+	if cnt := strings.Count(diff, "changetype: moddn\n"); cnt != 1 {
+		t.Fatalf("%s failed: want 1 moddn record, got %d:\n%s", t.Name(), cnt, diff)
+	} else if strings.Contains(diff, "n=5,n=999") {
+		t.Fatalf("%s failed: unexpected record for renamed child:\n%s", t.Name(), diff)
+	}
+}
+
+func TestImportFS(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+
+	dit.PrimeITUT()
+	dit.PrimeISO()
+	dit.PrimeJointISOITUT()
+
+	if err := dit.ImportFS(testFS, ImportList{
+		`smifile`:  `testdata/iana.xml`,
+		`ldapfile`: `testdata/ldap.xml`,
+		`penfile`:  `testdata/pen.txt`,
+	}); err != nil {
+		t.Fatalf("%s failed: unable to import one or more files: %v", t.Name(), err)
+	}
+
+	checkLDIF(t, dit, dit.Write(true, true, true).String())
+}
+
+func TestImport_concurrent(t *testing.T) {
+	imp := ImportList{
+		`smifile`:  `testdata/iana.xml`,
+		`ldapfile`: `testdata/ldap.xml`,
+		`penfile`:  `testdata/pen.txt`,
+		`csvfile`:  `testdata/custom.csv`,
+	}
+
+	var want, got string
+	for _, concurrent := range []bool{false, true} {
+		cfg := radir.NewFactoryDefaultDUAConfig()
+		dit := New(cfg.Profile())
+		dit.PrimeITUT()
+		dit.PrimeISO()
+		dit.PrimeJointISOITUT()
+		dit.SetImportOptions(ImportOptions{Concurrent: concurrent})
+
+		if err := dit.ImportFS(testFS, imp); err != nil {
+			t.Fatalf("%s failed: unable to import (concurrent: %t): %v",
+				t.Name(), concurrent, err)
+		}
+
+		if concurrent {
+			got = dit.Write(true, true, true).String()
+		} else {
+			want = dit.Write(true, true, true).String()
+		}
+	}
+
+	if got != want {
+		t.Fatalf("%s failed: concurrent import differs from sequential import (%d != %d bytes)",
+			t.Name(), len(got), len(want))
+	}
+}
+
+func TestRADIT_concurrentAccess(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+	dit.PrimeISO()
+	if err := dit.ImportReaders(ImportSources{
+		`penfile`: bytes.NewReader(testPENTXT),
+	}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	// Exports which include registrants are readers alike, and
+	// must not contend with one another, nor with an import.
+	opts := WriteOptions{Bases: []string{`iso`}, Registrants: true}
+	writers := []func() error{
+		func() (err error) { _, err = dit.WriteLDIF(io.Discard, opts); return },
+		func() (err error) { _, err = dit.WriteJSON(io.Discard, opts); return },
+		func() (err error) { _, err = dit.WriteCSV(io.Discard, CSVOptions{WriteOptions: opts}); return },
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 9; i++ {
+		wg.Add(1)
+		go func(write func() error) {
+			defer wg.Done()
+			errs <- write()
+		}(writers[i%len(writers)])
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		errs <- dit.ImportFS(testFS, ImportList{`csvfile`: `testdata/custom.csv`})
+	}()
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("%s failed: %v", t.Name(), err)
+		}
+	}
+}
+
+func TestImport_keepObsolete(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+	dit.PrimeISO()
+	dit.SetImportOptions(ImportOptions{KeepObsolete: true})
+
+	if !dit.ImportOptions().KeepObsolete {
+		t.Fatalf("%s failed: import options not retained", t.Name())
+	}
+
+	if err := dit.ImportReaders(ImportSources{
+		`smifile`: bytes.NewReader(testSMIXML),
+	}); err != nil {
+		t.Fatalf("%s failed: unable to import SMI source: %v", t.Name(), err)
+	}
+
+	for _, skip := range dit.Report().Skipped {
+		if skip.Reason == `obsolete` {
+			t.Fatalf("%s failed: obsolete record skipped: %#v", t.Name(), skip)
+		}
+	}
+
+	var obsolete int
+	_, err := dit.WriteLDIF(io.Discard, WriteOptions{
+		Filter: func(reg *radir.Registration) bool {
+			if reg.Supplement().Status() == `OBSOLETE` {
+				obsolete++
+			}
+			return false
+		},
+	})
+	if err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if obsolete == 0 {
+		t.Fatalf("%s failed: no OBSOLETE registrations found", t.Name())
+	}
+}
+
+func TestImport_footnote(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+	dit.PrimeISO()
+	dit.SetImportOptions(ImportOptions{KeepObsolete: true})
+
+	if err := dit.ImportFS(testFS, ImportList{`smifile`: `testdata/footnote.xml`}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	const want = `Obsoleted by the DS1-MIB test footnote`
+	reg, err := dit.Lookup(`1.3.6.1.3.2`)
+	if err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if info := reg.Supplement().Info(); len(info) != 1 || info[0] != want {
+		t.Fatalf("%s failed: footnote not linked; want %q, got %q", t.Name(), want, info)
+	}
+
+	var buf bytes.Buffer
+	if _, err = dit.WriteLDIF(&buf, WriteOptions{Bases: []string{`1.3.6.1.3`}}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if strings.Count(buf.String(), want) != 1 {
+		t.Fatalf("%s failed: footnote absent from, or repeated within, output:\n%s",
+			t.Name(), buf.String())
+	}
+}
+
+func TestImportPEN_badHeader(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+	dit.PrimeISO()
+
+	for _, bogus := range []string{
+		``,
+		"PRIVATE ENTERPRISE NUMBERS\n\nDecimal\n| Organization\n| Contact\n",
+		"Prefix: iso.org.dod.internet.private (1.3.6.1.4)\n\nDecimal\n| Organization\n| | Contact\n| | | Email\n| | | | \n",
+	} {
+		if err := dit.ImportReaders(ImportSources{
+			`penfile`: strings.NewReader(bogus),
+		}); err == nil {
+			t.Fatalf("%s failed: expected error for bogus header %q", t.Name(), bogus)
+		}
+	}
+}
+
+func TestImportPEN_irregular(t *testing.T) {
+	const hdr = "Prefix: iso.org.dod.internet.private.enterprise (1.3.6.1.4.1)\n\n" +
+		"Decimal\n| Organization\n| | Contact\n| | | Email\n| | | | \n"
+
+	pen := hdr +
+		"0\n  Reserved\n    IANA\n      iana&iana.org\n" + // line 8
+		"2\n  Missing Email\n    Someone\n" + // line 12
+		"1\n  Out Of Order\n    Someone\n      x&example.com\n" + // line 15
+		"3\n    No Organization\n      y&example.com\n" // line 19
+
+	for _, strict := range []bool{false, true} {
+		cfg := radir.NewFactoryDefaultDUAConfig()
+		dit := New(cfg.Profile())
+		dit.PrimeISO()
+		dit.SetImportOptions(ImportOptions{StrictPEN: strict})
+
+		err := dit.ImportReaders(ImportSources{`penfile`: strings.NewReader(pen)})
+		if strict {
+			if err == nil || !strings.Contains(err.Error(), `line 12`) {
+				t.Fatalf("%s failed: expected line 12 error in strict mode, got %v", t.Name(), err)
+			}
+			continue
+		} else if err != nil {
+			t.Fatalf("%s failed: unexpected error in lenient mode: %v", t.Name(), err)
+		}
+
+		counts := dit.Report().Counts()[`penfile`]
+		if counts.Skipped != 2 || counts.Warnings != 1 {
+			t.Fatalf("%s failed: unexpected report counts: %#v", t.Name(), counts)
+		}
+
+		for dot, want := range map[string]bool{
+			`1.3.6.1.4.1.0`: true,
+			`1.3.6.1.4.1.1`: false,
+			`1.3.6.1.4.1.2`: true,
+			`1.3.6.1.4.1.3`: false,
+		} {
+			if got := !dit.dit.Resolve(dot).IsZero(); got != want {
+				t.Fatalf("%s failed: unexpected presence of %s; want %t, got %t",
+					t.Name(), dot, want, got)
+			}
+		}
+	}
+}
+
+func TestWriteSchema(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+	dit.PrimeISO()
+	if err := dit.ImportReaders(ImportSources{
+		`jsonfile`: strings.NewReader(`[{"dotNotation":"1.3.6.1.4.1.56521","identifier":"example",
+"children":[{"dotNotation":"1.3.6.1.4.1.56521.2","identifier":"schema",
+"children":[{"dotNotation":"1.3.6.1.4.1.56521.2.3","identifier":"at",
+"children":[{"dotNotation":"1.3.6.1.4.1.56521.2.3.1","identifier":"n","description":"Jesse's number"}]}]}]}]`),
+	}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	opts := SchemaOptions{
+		WriteOptions: WriteOptions{Bases: []string{`1.3.6.1.4.1.56521`}},
+		Stubs:        true,
+	}
+
+	for format, want := range map[SchemaFormat][]string{
+		SchemaSlapd: {
+			"objectIdentifier example 1.3.6.1.4.1.56521\n",
+			"objectIdentifier schema example:2\n",
+			"objectIdentifier at schema:3\n",
+			"attributetype ( at:1 NAME 'n' DESC 'Jesse\\27s number' )\n",
+		},
+		SchemaOLC: {
+			"dn: cn=radit,cn=schema,cn=config\n",
+			"olcObjectIdentifier: {1}schema example:2\n",
+			"olcAttributeTypes: {0}( at:1 NAME 'n' DESC 'Jesse\\27s number' )\n",
+		},
+		Schema389: {
+			"dn: cn=schema\nobjectClass: top\nobjectClass: ldapSubentry\nobjectClass: subschema\ncn: schema\n",
+			"attributeTypes: ( 1.3.6.1.4.1.56521.2.3.1 NAME 'n' DESC 'Jesse\\27s number' X-ORIGIN 'user defined' )\n",
+		},
+	} {
+		var buf bytes.Buffer
+		opts.Format = format
+		if _, err := dit.WriteSchema(&buf, opts); err != nil {
+			t.Fatalf("%s failed: %v", t.Name(), err)
+		}
+
+		for _, line := range want {
+			if !strings.Contains(buf.String(), line) {
+				t.Fatalf("%s failed: format %d output lacks %q:\n%s",
+					t.Name(), format, line, buf.String())
+			}
+		}
+	}
+
+	// Schema389 implies Stubs, lest the entry bear no definitions.
+	var buf bytes.Buffer
+	opts.Format, opts.Stubs = Schema389, false
+	if _, err := dit.WriteSchema(&buf, opts); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if !strings.Contains(buf.String(), "attributeTypes: ( 1.3.6.1.4.1.56521.2.3.1 ") {
+		t.Fatalf("%s failed: 389 output lacks definitions without Stubs:\n%s", t.Name(), buf.String())
+	}
+
+	if _, err := dit.WriteSchema(io.Discard, SchemaOptions{Format: Schema389 + 1}); err == nil {
+		t.Fatalf("%s failed: expected error for unknown format", t.Name())
+	}
+}
+
+func TestWriteGo(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+	dit.PrimeISO()
+	if err := dit.ImportReaders(ImportSources{
+		`jsonfile`: strings.NewReader(`[{"dotNotation":"1.3.6.1.4.1.56521","identifier":"oid-directory",
+"children":[{"dotNotation":"1.3.6.1.4.1.56521.2","description":"Schema",
+"uris":["https://www.rfc-editor.org/rfc/rfc4512"],
+"children":[{"dotNotation":"1.3.6.1.4.1.56521.2.3","identifier":"at","status":"OBSOLETE"}]}]}]`),
+	}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := dit.WriteGo(&buf, GoOptions{
+		WriteOptions: WriteOptions{Bases: []string{`1.3.6.1.4.1.56521`}},
+		Package:      `oids`,
+		ASN1:         true,
+	}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"package oids\n",
+		"\tOidDirectory OID = \"1.3.6.1.4.1.56521\"\n",
+		"\t// OidDirectory2 is 1.3.6.1.4.1.56521.2.\n",
+		"\t// See https://www.rfc-editor.org/rfc/rfc4512\n",
+		"\t// Deprecated: this registration is OBSOLETE.\n",
+		"\tOidDirectory2At OID = \"1.3.6.1.4.1.56521.2.3\"\n",
+		"\tOidDirectory2AtASN1 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 56521, 2, 3}\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("%s failed: output lacks %q:\n%s", t.Name(), want, out)
+		}
+	}
+
+	if _, err := dit.WriteGo(io.Discard, GoOptions{Package: `not-a-package`}); err == nil {
+		t.Fatalf("%s failed: expected error for invalid package name", t.Name())
+	} else if _, err = dit.WriteGo(io.Discard, GoOptions{Prefix: `oid`}); err == nil {
+		t.Fatalf("%s failed: expected error for unexported prefix", t.Name())
+	}
+
+	// Arcs beyond the range of an int must not produce
+	// uncompilable asn1.ObjectIdentifier literals.
+	dit.PrimeJointISOITUT()
+	if err := dit.ImportReaders(ImportSources{
+		`jsonfile`: strings.NewReader(`[{"dotNotation":"2.25.329800735698586629295641978511506172918","identifier":"uuid"}]`),
+	}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	buf.Reset()
+	if _, err := dit.WriteGo(&buf, GoOptions{
+		WriteOptions: WriteOptions{Bases: []string{`2.25.329800735698586629295641978511506172918`}},
+		ASN1:         true,
+	}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	out = buf.String()
+	if !strings.Contains(out, "\tUuid OID = \"2.25.329800735698586629295641978511506172918\"\n") {
+		t.Fatalf("%s failed: output lacks UUID constant:\n%s", t.Name(), out)
+	} else if strings.Contains(out, `asn1`) {
+		t.Fatalf("%s failed: unexpected asn1 content:\n%s", t.Name(), out)
+	}
+}
+
+func TestReadProfileConfig(t *testing.T) {
+	want := ProfileConfig{
+		RegistrationBase:  `ou=Registrations,o=rA`,
+		RegistrantBase:    `ou=Registrants,o=rA`,
+		Model:             Model3D,
+		RegistrantsPolicy: PolicyDedicated,
+	}
+
+	for _, src := range []string{
+		`{"registrationBase":"ou=Registrations,o=rA","registrantBase":"ou=Registrants,o=rA",
+"model":"3D","registrantsPolicy":"dedicated"}`,
+		"---\n# Production layout\nregistrationBase: ou=Registrations,o=rA\n" +
+			"registrantBase: 'ou=Registrants,o=rA'  # quoted\n" +
+			"model: 3D\nregistrantsPolicy: \"dedicated\"\n",
+	} {
+		got, err := ReadProfileConfig(strings.NewReader(src))
+		if err != nil {
+			t.Fatalf("%s failed: %v", t.Name(), err)
+		} else if got != want {
+			t.Fatalf("%s failed:\n\twant: %#v\n\tgot:  %#v", t.Name(), want, got)
+		}
+	}
+
+	for _, src := range []string{
+		``,
+		`{"registrationBase":"ou=Registrations,o=rA","unknown":true}`,
+		"unknown: value\n",
+		"registrationBase:\n  nested: value\n",
+		"model: 4D\n",
+		"registrationBase: not a DN\n",
+		"registrantsPolicy: combined\nregistrantBase: ou=Registrants,o=rA\n",
+		"registrationBase: ou=X,o=rA\nregistrantBase: ou=x,o=rA\n",
+	} {
+		if _, err := ReadProfileConfig(strings.NewReader(src)); err == nil {
+			t.Fatalf("%s failed: expected error for %q", t.Name(), src)
+		}
+	}
+}
+
+func TestWriteTree(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+	dit.PrimeISO()
+	if err := dit.ImportReaders(ImportSources{
+		`jsonfile`: strings.NewReader(`[{"dotNotation":"1.3.6.1.4.1.56521","identifier":"example",
+"children":[{"dotNotation":"1.3.6.1.4.1.56521.1","identifier":"one",
+"children":[{"dotNotation":"1.3.6.1.4.1.56521.1.1"}]},
+{"dotNotation":"1.3.6.1.4.1.56521.2","identifier":"two","status":"OBSOLETE"},
+{"dotNotation":"1.3.6.1.4.1.56521.3","identifier":"three"}]}]`),
+	}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := dit.WriteTree(&buf, TreeOptions{
+		WriteOptions: WriteOptions{Bases: []string{`1.3.6.1.4.1.56521`}, MaxDepth: 1},
+		MaxChildren:  2,
+	}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	want := "56521 (example)\n" +
+		"├── 1 (one) [+1 children]\n" +
+		"├── 2 (two)\n" +
+		"└── … 1 more\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("%s failed:\nwant:\n%s\ngot:\n%s", t.Name(), want, got)
+	}
+
+	buf.Reset()
+	if _, err := dit.WriteTree(&buf, TreeOptions{
+		WriteOptions: WriteOptions{Bases: []string{`1.3.6.1.4.1.56521`}, MaxDepth: 1},
+		Indent:       true,
+		Status:       true,
+	}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if got := buf.String(); !strings.Contains(got, "\n  2 (two)"+strings.Repeat(` `, 16)+"OBSOLETE\n") {
+		t.Fatalf("%s failed: unexpected indented tree:\n%s", t.Name(), got)
+	}
+
+	// Children omitted by the Filter must not be counted beyond MaxDepth.
+	buf.Reset()
+	if _, err := dit.WriteTree(&buf, TreeOptions{
+		WriteOptions: WriteOptions{
+			Bases:    []string{`1.3.6.1.4.1.56521`},
+			MaxDepth: 1,
+			Filter: func(reg *radir.Registration) bool {
+				return reg.X680().DotNotation() != `1.3.6.1.4.1.56521.1.1`
+			},
+		},
+	}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	want = "56521 (example)\n" +
+		"├── 1 (one)\n" +
+		"├── 2 (two)\n" +
+		"└── 3 (three)\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("%s failed:\nwant:\n%s\ngot:\n%s", t.Name(), want, got)
+	}
+}
+
+func TestWriteDiagram(t *testing.T) {
+	cfg := radir.NewFactoryDefaultDUAConfig()
+	dit := New(cfg.Profile())
+	dit.PrimeISO()
+	if err := dit.ImportReaders(ImportSources{
+		`jsonfile`: strings.NewReader(`[{"dotNotation":"1.3.6.1.4.1.56521","identifier":"example",
+"children":[{"dotNotation":"1.3.6.1.4.1.56521.1","identifier":"one",
+"children":[{"dotNotation":"1.3.6.1.4.1.56521.1.1"}]},
+{"dotNotation":"1.3.6.1.4.1.56521.2","identifier":"two","status":"OBSOLETE"},
+{"dotNotation":"1.3.6.1.4.1.56521.3","identifier":"three"}]}]`),
+	}); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	opts := DiagramOptions{
+		WriteOptions: WriteOptions{Bases: []string{`1.3.6.1.4.1.56521`}, MaxDepth: 1},
+		MaxChildren:  2,
+		Color:        ColorByStatus,
+	}
+
+	var dot, mmd bytes.Buffer
+	if _, err := dit.WriteDOT(&dot, opts); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	} else if _, err = dit.WriteMermaid(&mmd, opts); err != nil {
+		t.Fatalf("%s failed: %v", t.Name(), err)
+	}
+
+	for _, want := range []string{
+		"digraph radit {\n",
+		"  n1_3_6_1_4_1_56521 [label=\"example(56521)\"];\n",
+		"  n1_3_6_1_4_1_56521_2 [label=\"two(2)\" style=filled fillcolor=\"#d3d3d3\"];\n",
+		"  n1_3_6_1_4_1_56521_1_more [label=\"… 1 children\" style=dashed];\n",
+		"  n1_3_6_1_4_1_56521_more [label=\"… 1 more\" style=dashed];\n",
+		"  n1_3_6_1_4_1_56521 -> n1_3_6_1_4_1_56521_1;\n",
+	} {
+		if !strings.Contains(dot.String(), want) {
+			t.Fatalf("%s failed: DOT output lacks %q:\n%s", t.Name(), want, dot.String())
+		}
+	}
+
+	for _, want := range []string{
+		"graph TD\n",
+		"  n1_3_6_1_4_1_56521_1[\"one(1)\"]\n",
+		"  n1_3_6_1_4_1_56521 --> n1_3_6_1_4_1_56521_2\n",
+		"  class n1_3_6_1_4_1_56521_2 c-obsolete\n",
+	} {
+		if !strings.Contains(mmd.String(), want) {
+			t.Fatalf("%s failed: Mermaid output lacks %q:\n%s", t.Name(), want, mmd.String())
+		}
+	}
+
+	if strings.Contains(dot.String(), "three") {
+		t.Fatalf("%s failed: MaxChildren not honored", t.Name())
+	}
+
+	// Filtered children must not count against MaxChildren,
+	// and the summary of a filtered base must be retained.
+	for _, strukt := range []struct {
+		Filter func(*radir.Registration) bool
+		Want   []string
+		Reject []string
+	}{
+		{
+			Filter: func(reg *radir.Registration) bool { return reg.X680().Identifier() != `two` },
+			Want:   []string{`three(3)`},
+			Reject: []string{`two(2)`, `… 1 more`},
+		},
+		{
+			Filter: func(reg *radir.Registration) bool { return reg.X680().Identifier() != `example` },
+			Want: []string{
+				"  n1_3_6_1_4_1_56521_1 [label=\"one(1)\"];\n",
+				"  n1_3_6_1_4_1_56521_more [label=\"… 1 more\" style=dashed];\n",
+			},
+			Reject: []string{`example(56521)`, `three(3)`},
+		},
+		{
+			Filter: func(reg *radir.Registration) bool {
+				return reg.X680().DotNotation() != `1.3.6.1.4.1.56521.1.1`
+			},
+			Want:   []string{`one(1)`},
+			Reject: []string{`… 1 children`},
+		},
+	} {
+		opts.Filter = strukt.Filter
+		dot.Reset()
+		if _, err := dit.WriteDOT(&dot, opts); err != nil {
+			t.Fatalf("%s failed: %v", t.Name(), err)
+		}
+
+		for _, want := range strukt.Want {
+			if !strings.Contains(dot.String(), want) {
+				t.Fatalf("%s failed: DOT output lacks %q:\n%s", t.Name(), want, dot.String())
+			}
+		}
+
+		for _, reject := range strukt.Reject {
+			if strings.Contains(dot.String(), reject) {
+				t.Fatalf("%s failed: DOT output contains %q:\n%s", t.Name(), reject, dot.String())
+			}
+		}
+	}
 }
@@ -0,0 +1,361 @@
+package radit
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/oid-directory/go-radir"
+	"github.com/oid-directory/go-radit/internal/common"
+)
+
+/*
+SchemaFormat defines the dialect of the output produced by [RADIT.WriteSchema].
+*/
+type SchemaFormat uint8
+
+const (
+	SchemaSlapd SchemaFormat = iota // OpenLDAP slapd.conf(5) directives
+	SchemaOLC                       // OpenLDAP cn=config (slapd-config(5)) LDIF entry
+	Schema389                       // 389 Directory Server 99user.ldif entry
+)
+
+/*
+SchemaOptions contains the settings which govern the content produced by the
+[RADIT.WriteSchema] method.
+*/
+type SchemaOptions struct {
+	// WriteOptions governs the selection and order of registrations,
+	// as described for [RADIT.WriteLDIF]. Subentries and Registrants
+	// have no effect.
+	WriteOptions
+
+	// Format selects the output dialect. The zero value selects
+	// [SchemaSlapd].
+	Format SchemaFormat
+
+	// Name contains the name of the cn=config schema entry written
+	// under [SchemaOLC], e.g.: "oid-directory". A zero value selects
+	// "radit".
+	Name string
+
+	// Stubs writes definition stubs for registrations beneath arcs
+	// which denote attribute types, object classes or LDAP syntaxes,
+	// rather than objectIdentifier macros. Stubs are always written
+	// under [Schema389].
+	Stubs bool
+}
+
+/*
+Kinds of schema definitions, determined by the identifier of the superior
+of each registration.
+*/
+const (
+	schemaMacro = iota
+	schemaAttributeType
+	schemaObjectClass
+	schemaSyntax
+)
+
+/*
+schemaKinds maps the lower-case identifiers of arcs which are conventionally
+used to enumerate schema definitions to the kind of definition enumerated.
+*/
+var schemaKinds = map[string]int{
+	`at`:             schemaAttributeType,
+	`attributetype`:  schemaAttributeType,
+	`attributetypes`: schemaAttributeType,
+	`attribute-type`: schemaAttributeType,
+	`attributes`:     schemaAttributeType,
+	`oc`:             schemaObjectClass,
+	`objectclass`:    schemaObjectClass,
+	`objectclasses`:  schemaObjectClass,
+	`object-class`:   schemaObjectClass,
+	`syntax`:         schemaSyntax,
+	`syntaxes`:       schemaSyntax,
+	`ldap-syntax`:    schemaSyntax,
+	`ls`:             schemaSyntax,
+}
+
+/*
+schemaDef contains a single macro or definition stub.
+*/
+type schemaDef struct {
+	kind  int
+	name  string // macro name or NAME value
+	macro string // OID in macro form, e.g.: name:1 or name:4.1
+	oid   string // OID in numeric form
+	desc  string
+}
+
+/*
+schemaWriter collects the macros and definition stubs of a subtree.
+*/
+type schemaWriter struct {
+	opts   SchemaOptions
+	macros map[string]bool
+	defs   []schemaDef
+}
+
+/*
+WriteSchema writes objectIdentifier macros, and optionally definition stubs,
+derived from the registrations present within the receiver instance to the
+input [io.Writer] instance, returning the number of bytes written alongside
+an error, if any. It is recommended that a base, such as "1.3.6.1.4.1.56521",
+be specified, as a full tree would yield a great many macros.
+
+A macro is written for each registration bearing an identifier which is also
+a valid LDAP descr, e.g.:
+
+	objectIdentifier oid-directory 1.3.6.1.4.1.56521.101
+	objectIdentifier schema oid-directory:2
+
+Macro names must be unique, thus a name already in use is prefixed with that
+of the superior macro, e.g.: "ds-schema". The value of each macro is expressed
+relative to its nearest superior macro, if any.
+
+If [SchemaOptions.Stubs] is set, registrations beneath an arc identified as
+"at", "oc" or "ldap-syntax" (or similar) are written as attribute type, object
+class or LDAP syntax stubs, rather than as macros, e.g.:
+
+	attributetype ( schema-at:1 NAME 'n' DESC 'number form' )
+
+Such stubs lack SYNTAX, SUP, MUST and similar clauses, and must be completed
+before use.
+
+As 389 Directory Server does not support macros, output in the [Schema389]
+format consists of stubs alone, in which the numeric form of each OID is used,
+whether or not [SchemaOptions.Stubs] is set.
+*/
+func (r *RADIT) WriteSchema(w io.Writer, opts SchemaOptions) (n int64, err error) {
+	if r.IsZero() {
+		err = errors.New("RADIT instance is nil, aborting write")
+		return
+	} else if w == nil {
+		err = errors.New("io.Writer instance is nil, aborting write")
+		return
+	} else if opts.Format > Schema389 {
+		err = errors.New("Unknown schema format, aborting write")
+		return
+	} else if opts.Format == Schema389 {
+		// Macros are not supported; stubs are all there is.
+		opts.Stubs = true
+	}
+
+	bases, unlock, err := r.prepare(opts.WriteOptions)
+	defer unlock()
+	if err != nil {
+		return
+	}
+
+	sw := &schemaWriter{opts: opts, macros: make(map[string]bool)}
+	for _, base := range bases {
+		sw.collect(base, ``, nil, schemaMacro, 0)
+	}
+
+	cw := &countWriter{w: w}
+	_, err = io.WriteString(cw, sw.render())
+	n = cw.n
+
+	return
+}
+
+/*
+collect records the macro or stub of the input registration, followed by
+those of its descendants. The input macro and arcs describe the OID of the
+input registration relative to the nearest superior macro, while the input
+kind is that implied by the identifier of the immediate superior.
+*/
+func (r *schemaWriter) collect(reg *radir.Registration, macro string, arcs []string, kind, depth int) {
+	if reg.IsZero() {
+		return
+	}
+
+	x680 := reg.X680()
+	dot, id := x680.DotNotation(), x680.Identifier()
+	if macro != "" {
+		arcs = append(append([]string{}, arcs...), x680.N())
+	}
+
+	relative := dot
+	if macro != "" {
+		relative = macro + `:` + strings.Join(arcs, `.`)
+	}
+
+	omit, deep := skip(reg, depth, r.opts.WriteOptions)
+	if !omit {
+		def := schemaDef{
+			kind:  schemaMacro,
+			macro: relative,
+			oid:   dot,
+			desc:  reg.Description(),
+		}
+
+		if r.opts.Stubs && kind != schemaMacro {
+			def.kind = kind
+			if isDescr(id) {
+				def.name = id
+			}
+			r.defs = append(r.defs, def)
+		} else if name := r.macro(id, macro); name != "" {
+			def.name = name
+			r.defs = append(r.defs, def)
+			macro, arcs = name, nil
+		}
+	}
+
+	if deep {
+		return
+	}
+
+	kind = schemaKinds[strings.ToLower(id)]
+	kids := reg.Children()
+	for i := 0; i < kids.Len(); i++ {
+		r.collect(kids.Index(i), macro, arcs, kind, depth+1)
+	}
+}
+
+/*
+macro returns a unique macro name derived from the input identifier, which
+is recorded as having been assigned. A zero string is returned if no such
+name could be derived.
+*/
+func (r *schemaWriter) macro(id, sup string) (name string) {
+	if !isDescr(id) {
+		return
+	}
+
+	for _, try := range []string{id, sup + `-` + id} {
+		if isDescr(try) && !r.macros[strings.ToLower(try)] {
+			name = try
+			r.macros[strings.ToLower(name)] = true
+			break
+		}
+	}
+
+	return
+}
+
+/*
+render returns the collected macros and stubs in the requested format.
+*/
+func (r *schemaWriter) render() string {
+	var b strings.Builder
+
+	switch r.opts.Format {
+	case SchemaSlapd:
+		directives := map[int]string{
+			schemaMacro:         `objectIdentifier`,
+			schemaAttributeType: `attributetype`,
+			schemaObjectClass:   `objectclass`,
+			schemaSyntax:        `ldapsyntax`,
+		}
+		for _, def := range r.defs {
+			if def.kind == schemaMacro {
+				b.WriteString(directives[def.kind] + ` ` + def.name + ` ` + def.macro + "\n")
+			} else {
+				b.WriteString(directives[def.kind] + ` ` + def.definition(def.macro, ``) + "\n")
+			}
+		}
+	case SchemaOLC:
+		name := r.opts.Name
+		if name == "" {
+			name = `radit`
+		}
+		b.WriteString(common.LDIFLine(`dn`, `cn=`+name+`,cn=schema,cn=config`))
+		b.WriteString(common.LDIFLine(`objectClass`, `olcSchemaConfig`))
+		b.WriteString(common.LDIFLine(`cn`, name))
+		for _, strukt := range []struct {
+			kind int
+			typ  string
+		}{
+			{schemaMacro, `olcObjectIdentifier`},
+			{schemaSyntax, `olcLdapSyntaxes`},
+			{schemaAttributeType, `olcAttributeTypes`},
+			{schemaObjectClass, `olcObjectClasses`},
+		} {
+			var idx int
+			for _, def := range r.defs {
+				if def.kind != strukt.kind {
+					continue
+				}
+
+				val := def.definition(def.macro, ``)
+				if def.kind == schemaMacro {
+					val = def.name + ` ` + def.macro
+				}
+				b.WriteString(common.LDIFLine(strukt.typ, `{`+strconv.Itoa(idx)+`}`+val))
+				idx++
+			}
+		}
+	case Schema389:
+		b.WriteString(common.LDIFLine(`dn`, `cn=schema`))
+		for _, oc := range []string{`top`, `ldapSubentry`, `subschema`} {
+			b.WriteString(common.LDIFLine(`objectClass`, oc))
+		}
+		b.WriteString(common.LDIFLine(`cn`, `schema`))
+		for _, strukt := range []struct {
+			kind int
+			typ  string
+		}{
+			{schemaSyntax, `ldapSyntaxes`},
+			{schemaAttributeType, `attributeTypes`},
+			{schemaObjectClass, `objectClasses`},
+		} {
+			for _, def := range r.defs {
+				if def.kind == strukt.kind {
+					b.WriteString(common.LDIFLine(strukt.typ,
+						def.definition(def.oid, ` X-ORIGIN 'user defined'`)))
+				}
+			}
+		}
+	}
+
+	return b.String()
+}
+
+/*
+definition returns the definition stub of the receiver instance, bearing the
+input OID and extension.
+*/
+func (r schemaDef) definition(oid, ext string) string {
+	def := `( ` + oid
+	if r.name != "" && r.kind != schemaSyntax {
+		def += ` NAME '` + r.name + `'`
+	}
+
+	if desc := common.CondenseWHSP(strings.ReplaceAll(r.desc, "\n", ` `)); desc != "" {
+		def += ` DESC '` + escapeQDString(desc) + `'`
+	}
+
+	return def + ext + ` )`
+}
+
+/*
+escapeQDString escapes the input value for use within a qdstring (RFC 4512).
+*/
+func escapeQDString(in string) string {
+	return strings.NewReplacer(`\`, `\5C`, `'`, `\27`).Replace(in)
+}
+
+/*
+isDescr returns a Boolean value indicative of the input value being a valid
+descr (RFC 4512): an ALPHA followed by any number of ALPHA, DIGIT or HYPHEN
+characters.
+*/
+func isDescr(in string) bool {
+	if in == "" {
+		return false
+	}
+
+	for i, c := range in {
+		alpha := 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+		if i == 0 && !alpha ||
+			!(alpha || '0' <= c && c <= '9' || c == '-') {
+			return false
+		}
+	}
+
+	return true
+}
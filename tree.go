@@ -0,0 +1,249 @@
+package radit
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/oid-directory/go-radir"
+	"github.com/oid-directory/go-radit/internal/common"
+)
+
+/*
+TreeOptions contains the settings which govern the content produced by the
+[RADIT.WriteTree] method.
+*/
+type TreeOptions struct {
+	// WriteOptions governs the selection and order of registrations,
+	// as described for [RADIT.WriteLDIF]. Subentries and Registrants
+	// have no effect. Registrations omitted by the Filter are replaced
+	// by their children.
+	WriteOptions
+
+	// MaxChildren limits the number of children printed beneath any
+	// one registration, the remainder being summarized on a single
+	// line. A value of zero imposes no limit.
+	MaxChildren int
+
+	// Indent draws the tree using indentation alone, rather than the
+	// default box-drawing characters.
+	Indent bool
+
+	// Description, Status and Source append a column bearing the
+	// description, status or source of each registration. Columns
+	// are aligned, thus output is buffered until complete.
+	Description bool
+	Status      bool
+	Source      bool
+}
+
+/*
+treeWriter writes the lines of a rendered tree, retaining the first error
+encountered.
+*/
+type treeWriter struct {
+	w    io.Writer
+	err  error
+	opts TreeOptions
+	dit  *common.DIT
+}
+
+func (r *treeWriter) write(s string) {
+	if r.err == nil {
+		_, r.err = io.WriteString(r.w, s)
+	}
+}
+
+/*
+WriteTree writes the structure of the registrations present within the
+receiver instance to the input [io.Writer] instance, one "n (identifier)"
+line per registration, returning the number of bytes written alongside
+an error, if any. For example:
+
+	1 (iso)
+	└── 3 (identified-organization)
+	    └── 6 (dod)
+	        └── 1 (internet)
+	            ├── 1 (directory)
+	            ├── 2 (mgmt) [+1 children]
+	            └── … 4 more
+
+A registration whose children lie beyond [WriteOptions.MaxDepth] bears a
+count of those of its children not omitted by the Filter, while children in excess of [TreeOptions.MaxChildren]
+are summarized as shown above. These limits are recommended for large
+branches, such as that of the PEN registry.
+*/
+func (r *RADIT) WriteTree(w io.Writer, opts TreeOptions) (n int64, err error) {
+	if r.IsZero() {
+		err = errors.New("RADIT instance is nil, aborting write")
+		return
+	} else if w == nil {
+		err = errors.New("io.Writer instance is nil, aborting write")
+		return
+	} else if opts.MaxChildren < 0 || opts.MaxDepth < 0 {
+		err = errors.New("Negative tree limit, aborting write")
+		return
+	}
+
+	bases, unlock, err := r.prepare(opts.WriteOptions)
+	defer unlock()
+	if err != nil {
+		return
+	}
+
+	cw := &countWriter{w: w}
+	tw := &treeWriter{w: cw, opts: opts, dit: r.dit}
+
+	var tab *tabwriter.Writer
+	if opts.Description || opts.Status || opts.Source {
+		tab = tabwriter.NewWriter(cw, 0, 4, 2, ' ', 0)
+		tw.w = tab
+	}
+
+	for _, base := range bases {
+		for _, node := range visible(base, 0, opts.WriteOptions) {
+			tw.render(node, ``, ``, ``)
+		}
+	}
+
+	if err = tw.err; err == nil && tab != nil {
+		err = tab.Flush()
+	}
+	n = cw.n
+
+	return
+}
+
+/*
+treeNode describes a registration to be rendered at a given depth.
+*/
+type treeNode struct {
+	reg   *radir.Registration
+	depth int
+}
+
+/*
+visible returns the input registration, or its children if the registration
+is omitted by the Filter of the input [WriteOptions], as instances of treeNode.
+*/
+func visible(reg *radir.Registration, depth int, opts WriteOptions) (nodes []treeNode) {
+	if reg.IsZero() {
+		return
+	}
+
+	omit, deep := skip(reg, depth, opts)
+	if !omit {
+		nodes = append(nodes, treeNode{reg, depth})
+	} else if !deep {
+		nodes = children(reg, depth, opts)
+	}
+
+	return
+}
+
+/*
+children returns the visible children of the input registration.
+*/
+func children(reg *radir.Registration, depth int, opts WriteOptions) (nodes []treeNode) {
+	kids := reg.Children()
+	for i := 0; i < kids.Len(); i++ {
+		nodes = append(nodes, visible(kids.Index(i), depth+1, opts)...)
+	}
+
+	return
+}
+
+/*
+hidden returns the number of visible children of the input registration,
+regardless of the MaxDepth of the input [WriteOptions]. This is used to
+summarize registrations whose children lie beyond that depth.
+*/
+func hidden(reg *radir.Registration, depth int, opts WriteOptions) int {
+	opts.MaxDepth = 0
+	return len(children(reg, depth, opts))
+}
+
+/*
+render writes the line of the input node, preceded by the input prefix and
+connector, followed by those of its descendants. The input indent is the
+prefix of the node's children.
+*/
+func (r *treeWriter) render(node treeNode, prefix, connector, indent string) {
+	if r.err != nil {
+		return
+	}
+
+	x680 := node.reg.X680()
+	line := prefix + connector + x680.N()
+	if id := x680.Identifier(); id != "" {
+		line += ` (` + id + `)`
+	}
+
+	_, deep := skip(node.reg, node.depth, r.opts.WriteOptions)
+
+	var kids []treeNode
+	if !deep {
+		kids = children(node.reg, node.depth, r.opts.WriteOptions)
+	} else if cnt := hidden(node.reg, node.depth, r.opts.WriteOptions); cnt > 0 {
+		line += ` [+` + strconv.Itoa(cnt) + ` children]`
+	}
+
+	r.write(line + r.columns(node.reg) + "\n")
+
+	shown := len(kids)
+	if max := r.opts.MaxChildren; max > 0 && shown > max {
+		shown = max
+	}
+
+	prefix += indent
+	for i := 0; i < shown; i++ {
+		last := i == len(kids)-1
+		r.render(kids[i], prefix, r.connector(last), r.indent(last))
+	}
+
+	if more := len(kids) - shown; more > 0 {
+		r.write(prefix + r.connector(true) + `… ` + strconv.Itoa(more) + " more\n")
+	}
+}
+
+func (r *treeWriter) connector(last bool) string {
+	switch {
+	case r.opts.Indent:
+		return `  `
+	case last:
+		return `└── `
+	}
+
+	return `├── `
+}
+
+func (r *treeWriter) indent(last bool) string {
+	switch {
+	case r.opts.Indent:
+		return `  `
+	case last:
+		return `    `
+	}
+
+	return `│   `
+}
+
+/*
+columns returns the requested columns of the input registration, each being
+preceded by a tab.
+*/
+func (r *treeWriter) columns(reg *radir.Registration) (cols string) {
+	if r.opts.Description {
+		cols += "\t" + common.CondenseWHSP(strings.ReplaceAll(reg.Description(), "\n", ` `))
+	}
+	if r.opts.Status {
+		cols += "\t" + reg.Supplement().Status()
+	}
+	if r.opts.Source {
+		cols += "\t" + r.dit.Source(reg)
+	}
+
+	return
+}
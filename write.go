@@ -0,0 +1,298 @@
+package radit
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/oid-directory/go-radir"
+)
+
+/*
+WriteOptions contains the settings which govern the content produced by
+the [RADIT.WriteLDIF] method.
+
+The zero value writes all three roots in their entirety, without sorting,
+spatial ordering, subentries or registrants.
+*/
+type WriteOptions struct {
+	// SortByNumberForm sorts each root, and all of its descendants,
+	// by number form magnitude prior to output.
+	SortByNumberForm bool
+
+	// SpatialXY orders all registrations according to number form
+	// along the X and Y axes prior to output.
+	SpatialXY bool
+
+	// Subentries includes the subentries of each registration.
+	Subentries bool
+
+	// Registrants includes registrant entries following those of the
+	// registrations. This has no effect unless the underlying profile
+	// operates under the terms of the "Dedicated Registrants Policy".
+	//
+	// When Bases is non-empty, only those registrants referenced by a
+	// written registration are included.
+	Registrants bool
+
+	// Bases limits output to the named roots or subtrees. Each value
+	// may be a root name ("itu-t", "iso" or "joint-iso-itu-t"), a root
+	// number form ("0", "1" or "2") or the dotNotation of any registered
+	// OID, such as "1.3.6.1.4.1". Bases which fall beneath another base
+	// are ignored. An empty slice selects all three roots.
+	Bases []string
+
+	// MaxDepth limits the number of levels written beneath each base.
+	// A value of zero imposes no limit.
+	MaxDepth int
+
+	// Filter, if non-nil, is called for each registration. Entries for
+	// which false is returned are omitted, though their descendants are
+	// still considered.
+	Filter func(*radir.Registration) bool
+}
+
+/*
+ldifWriter wraps an [io.Writer] instance, tallying the number of bytes
+written and retaining the first error encountered. Once an error has
+occurred, all subsequent writes are discarded.
+*/
+type ldifWriter struct {
+	w    io.Writer
+	n    int64
+	err  error
+	opts WriteOptions
+	aths map[string]bool // registrant DNs referenced by written entries
+}
+
+func (r *ldifWriter) write(s string) {
+	if r.err == nil && len(s) > 0 {
+		var n int
+		n, r.err = io.WriteString(r.w, s)
+		r.n += int64(n)
+	}
+}
+
+/*
+countWriter wraps an [io.Writer] instance, tallying the number of bytes
+written to it.
+*/
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (r *countWriter) Write(b []byte) (n int, err error) {
+	n, err = r.w.Write(b)
+	r.n += int64(n)
+	return
+}
+
+/*
+WriteLDIF writes the LDIF content present within the receiver instance
+to the input [io.Writer] instance, returning the number of bytes written
+alongside an error, if any.
+
+Unlike [RADIT.Write], this method does not assemble the dump in memory.
+Instead, each selected root or base is traversed depth-first and each
+entry is written to w as soon as it is produced, making this method
+suitable for use with files, compression streams and network connections.
+
+Registrant entries, if requested, are written last.
+
+Writes which neither sort nor spatially order the tree may proceed alongside
+other readers of the receiver instance.
+*/
+func (r *RADIT) WriteLDIF(w io.Writer, opts WriteOptions) (n int64, err error) {
+	if r.IsZero() {
+		err = errors.New("RADIT instance is nil, aborting write")
+		return
+	} else if w == nil {
+		err = errors.New("io.Writer instance is nil, aborting write")
+		return
+	}
+
+	bases, unlock, err := r.prepare(opts)
+	defer unlock()
+	if err != nil {
+		return
+	}
+
+	lw := &ldifWriter{w: w, opts: opts}
+	if len(opts.Bases) > 0 {
+		lw.aths = make(map[string]bool)
+	}
+
+	for i := 0; i < len(bases) && lw.err == nil; i++ {
+		_ = walk(bases[i], opts, lw.writeRegistration)
+	}
+
+	if opts.Registrants && r.dit.Profile().Dedicated() {
+		// DEDICATED registrants are in use; include in output.
+		aths := r.dit.Registrants()
+		for i := 0; i < aths.Len() && lw.err == nil; i++ {
+			if ath := aths.Index(i); lw.aths == nil || lw.aths[ath.DN()] {
+				lw.write(ath.LDIF())
+			}
+		}
+	}
+
+	n, err = lw.n, lw.err
+
+	return
+}
+
+/*
+prepare locks the receiver instance as appropriate for the input [WriteOptions]
+and returns the bases to be written, alongside a closure which releases the
+lock. The closure is always non-nil and must be called, even if an error is
+returned.
+*/
+func (r *RADIT) prepare(opts WriteOptions) (bases []*radir.Registration, unlock func(), err error) {
+	if opts.SortByNumberForm || opts.SpatialXY {
+		// Ordering modifies the tree.
+		r.dit.Lock()
+		unlock = r.dit.Unlock
+	} else {
+		r.dit.RLock()
+		unlock = r.dit.RUnlock
+	}
+
+	if bases, err = r.bases(opts.Bases); err == nil {
+		r.order(opts.SortByNumberForm, opts.SpatialXY)
+	}
+
+	return
+}
+
+/*
+order applies number form sorting and spatial ordering to all three roots,
+as requested.
+*/
+func (r *RADIT) order(sortByNumberForm, spatialXY bool) {
+	for _, root := range []*radir.Registration{
+		r.dit.ITUT(),
+		r.dit.ISO(),
+		r.dit.JointISOITUT(),
+	} {
+		if sortByNumberForm {
+			// sort the ENTIRE root by number form magnitude
+			root.SortByNumberForm(sortByNumberForm)
+		}
+
+		if spatialXY {
+			// Order ALL registrations according
+			// to number form along X and Y axes.
+			root.SetXAxes(spatialXY)
+			root.SetYAxes(spatialXY)
+		}
+	}
+}
+
+/*
+bases returns the *[radir.Registration] instances identified by the input
+root names, root numbers or dotNotation values. All three roots are returned
+if no values are provided. Values which reside beneath another requested
+value are discarded.
+*/
+func (r *RADIT) bases(in []string) (bases []*radir.Registration, err error) {
+	if len(in) == 0 {
+		bases = []*radir.Registration{
+			r.dit.ITUT(),
+			r.dit.ISO(),
+			r.dit.JointISOITUT(),
+		}
+		return
+	}
+
+	var dots []string
+	for _, base := range in {
+		reg := r.dit.Resolve(base)
+		if reg.IsZero() {
+			err = errors.New("Base '" + base + "' not found, aborting write")
+			return
+		}
+		bases = append(bases, reg)
+		dots = append(dots, reg.X680().DotNotation())
+	}
+
+	var uniq []*radir.Registration
+	for i := range bases {
+		var covered bool
+		for j := range bases {
+			if i != j && (dots[i] == dots[j] && j < i ||
+				strings.HasPrefix(dots[i], dots[j]+`.`)) {
+				covered = true
+				break
+			}
+		}
+
+		if !covered {
+			uniq = append(uniq, bases[i])
+		}
+	}
+	bases = uniq
+
+	return
+}
+
+/*
+writeRegistration writes the LDIF entry of the input *[radir.Registration]
+instance, returning the first error encountered by the receiver, if any. It
+is called by walk for each registration selected for output.
+*/
+func (r *ldifWriter) writeRegistration(reg *radir.Registration) error {
+	// A depth of zero limits the output to the entry
+	// (and, optionally, its subentries) alone.
+	r.write(reg.LDIF(0, r.opts.Subentries))
+
+	if r.aths != nil {
+		for _, dn := range reg.X660().CurrentAuthorities() {
+			r.aths[dn] = true
+		}
+	}
+
+	return r.err
+}
+
+/*
+skip returns Boolean values indicative of the input registration being
+omitted by the Filter, and of its children lying beyond the MaxDepth, of
+the input [WriteOptions].
+*/
+func skip(reg *radir.Registration, depth int, opts WriteOptions) (omit, deep bool) {
+	omit = opts.Filter != nil && !opts.Filter(reg)
+	deep = opts.MaxDepth > 0 && depth >= opts.MaxDepth
+	return
+}
+
+/*
+walk calls the input closure for the input registration, followed by its
+descendants, in depth-first order, honoring the Filter and MaxDepth of the
+input [WriteOptions]. The walk ends upon the first error returned by the
+closure.
+*/
+func walk(reg *radir.Registration, opts WriteOptions, visit func(*radir.Registration) error) error {
+	var funk func(*radir.Registration, int) error
+	funk = func(reg *radir.Registration, depth int) (err error) {
+		if reg.IsZero() {
+			return
+		}
+
+		omit, deep := skip(reg, depth, opts)
+		if !omit {
+			err = visit(reg)
+		}
+
+		if !deep {
+			kids := reg.Children()
+			for i := 0; i < kids.Len() && err == nil; i++ {
+				err = funk(kids.Index(i), depth+1)
+			}
+		}
+
+		return
+	}
+
+	return funk(reg, 0)
+}